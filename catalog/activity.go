@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// A RuntimeSource supplies a server's live activity. Unlike the catalog data
+// of a Source, it is never cached: every call queries the server afresh.
+type RuntimeSource interface {
+	Sessions(ctx context.Context) ([]*Session, error)           // client sessions, in every database
+	PreparedXacts(ctx context.Context) ([]*PreparedXact, error) // transactions prepared for two-phase commit
+}
+
+// Session contains information about a client session (backend).
+type Session struct {
+	pid         int           // process ID of the backend
+	user        string        // name of the logged-in user
+	database    string        // name of the connected database
+	application string        // application_name
+	state       string        // e.g. "active", "idle in transaction"
+	xactAge     time.Duration // age of the current transaction; zero if none
+	stateAge    time.Duration // time since the state last changed
+	waitEvent   string        // wait event type, e.g. "Lock"; empty if not waiting
+	waitingOn   string        // relation whose lock the session awaits, if any
+	query       string        // current or most recent query
+	blockedBy   []int32       // PIDs of the sessions blocking this one
+}
+
+func (s *Session) PID() int                { return s.pid }
+func (s *Session) User() string            { return s.user }
+func (s *Session) Database() string        { return s.database }
+func (s *Session) Application() string     { return s.application }
+func (s *Session) State() string           { return s.state }
+func (s *Session) XactAge() time.Duration  { return s.xactAge }
+func (s *Session) StateAge() time.Duration { return s.stateAge }
+func (s *Session) WaitEvent() string       { return s.waitEvent }
+func (s *Session) WaitingOn() string       { return s.waitingOn }
+func (s *Session) Query() string           { return s.query }
+func (s *Session) BlockedBy() []int32      { return s.blockedBy }
+
+// IsIdleInTransaction reports whether the session has an open transaction but
+// is not executing a query.
+func (s *Session) IsIdleInTransaction() bool {
+	return strings.HasPrefix(s.state, "idle in transaction")
+}
+
+// PreparedXact contains information about a transaction prepared for
+// two-phase commit, which holds its locks until it is committed or rolled back.
+type PreparedXact struct {
+	gid      string        // global identifier assigned by PREPARE TRANSACTION
+	age      time.Duration // time since it was prepared
+	owner    string        // name of the user that prepared it
+	database string        // name of the database in which it was prepared
+}
+
+func (p *PreparedXact) GID() string        { return p.gid }
+func (p *PreparedXact) Age() time.Duration { return p.age }
+func (p *PreparedXact) Owner() string      { return p.owner }
+func (p *PreparedXact) Database() string   { return p.database }
+
+// Sessions returns the client sessions connected to the server, other than
+// the one running the query.
+func (db *DB) Sessions(ctx context.Context) ([]*Session, error) {
+	var result []*Session
+	err := db.readOnly(ctx, "sessions", func(tx *pgx.Tx) (err error) {
+		result, err = loadSessions(ctx, tx, db.version)
+		return err
+	})
+	return result, err
+}
+
+// Returns the sessions; q.v. DB.Sessions.
+func loadSessions(ctx context.Context, tx *pgx.Tx, version Version) ([]*Session, error) {
+	sql, err := sqlSelectSessions.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []*Session
+	for rows.Next() {
+		var s Session
+		if err := scanSession(rows, &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &s)
+	}
+	return sessions, rows.Err()
+}
+
+// PreparedXacts returns the transactions prepared for two-phase commit in
+// every database of the cluster.
+func (db *DB) PreparedXacts(ctx context.Context) ([]*PreparedXact, error) {
+	var result []*PreparedXact
+	err := db.readOnly(ctx, "prepared transactions", func(tx *pgx.Tx) (err error) {
+		result, err = loadPreparedXacts(ctx, tx, db.version)
+		return err
+	})
+	return result, err
+}
+
+// Returns the prepared transactions; q.v. DB.PreparedXacts.
+func loadPreparedXacts(ctx context.Context, tx *pgx.Tx, version Version) ([]*PreparedXact, error) {
+	sql, err := sqlSelectPreparedXacts.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var xacts []*PreparedXact
+	for rows.Next() {
+		var p PreparedXact
+		if err := scanPreparedXact(rows, &p); err != nil {
+			return nil, err
+		}
+		xacts = append(xacts, &p)
+	}
+	return xacts, rows.Err()
+}
+
+// Converts a number of seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Before Postgres 10, pg_stat_activity only shows client backends.
+var sqlSelectSessions = catalogQuery{
+	name: "sessions",
+	variants: []sqlVariant{
+		{100000, sqlSelectSessionsTemplate + `
+   and a.backend_type = 'client backend'`},
+		{90600, sqlSelectSessionsTemplate},
+	},
+}
+
+const sqlSelectSessionsTemplate = `
+select a.pid,
+       coalesce(a.usename, ''),
+       coalesce(a.datname, ''),
+       coalesce(a.application_name, ''),
+       coalesce(a.state, ''),
+       coalesce(extract(epoch from clock_timestamp() - a.xact_start), 0)::float8,
+       coalesce(extract(epoch from clock_timestamp() - a.state_change), 0)::float8,
+       coalesce(a.wait_event_type, ''),
+       coalesce((select l.relation::regclass::text
+                   from pg_locks l
+                  where l.pid = a.pid
+                    and not l.granted
+                    and l.relation is not null
+                  limit 1), ''),
+       coalesce(a.query, ''),
+       pg_blocking_pids(a.pid)
+  from pg_stat_activity a
+ where a.pid <> pg_backend_pid()`
+
+func scanSession(sc scannable, s *Session) error {
+	var xactAge, stateAge float64 // seconds
+	err := sc.Scan(
+		&s.pid,         // pg_stat_activity.pid
+		&s.user,        // pg_stat_activity.usename
+		&s.database,    // pg_stat_activity.datname
+		&s.application, // pg_stat_activity.application_name
+		&s.state,       // pg_stat_activity.state
+		&xactAge,       // now - pg_stat_activity.xact_start
+		&stateAge,      // now - pg_stat_activity.state_change
+		&s.waitEvent,   // pg_stat_activity.wait_event_type
+		&s.waitingOn,   // pg_locks.relation, if not granted
+		&s.query,       // pg_stat_activity.query
+		&s.blockedBy,   // pg_blocking_pids(pid)
+	)
+	s.xactAge = seconds(xactAge)
+	s.stateAge = seconds(stateAge)
+	return err
+}
+
+var sqlSelectPreparedXacts = catalogQuery{
+	name: "prepared transactions",
+	variants: []sqlVariant{
+		{90600, `
+select p.gid,
+       extract(epoch from clock_timestamp() - p.prepared)::float8,
+       p.owner,
+       p.database
+  from pg_prepared_xacts p`},
+	},
+}
+
+func scanPreparedXact(sc scannable, p *PreparedXact) error {
+	var age float64 // seconds
+	err := sc.Scan(
+		&p.gid,      // pg_prepared_xacts.gid
+		&age,        // now - pg_prepared_xacts.prepared
+		&p.owner,    // pg_prepared_xacts.owner
+		&p.database, // pg_prepared_xacts.database
+	)
+	p.age = seconds(age)
+	return err
+}
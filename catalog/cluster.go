@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/jackc/pgx"
+)
+
+// Databases returns the names of the databases in the cluster that accept
+// connections, other than the templates, in alphabetical order. Unlike the
+// Source methods, it queries the server afresh on every call.
+func (db *DB) Databases(ctx context.Context) ([]string, error) {
+	var result []string
+	err := db.readOnly(ctx, "databases", func(tx *pgx.Tx) (err error) {
+		result, err = loadDatabases(ctx, tx, db.version)
+		return err
+	})
+	return result, err
+}
+
+// Returns the databases in the cluster; q.v. DB.Databases.
+func loadDatabases(ctx context.Context, tx *pgx.Tx, version Version) ([]string, error) {
+	sql, err := sqlSelectDatabases.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+var sqlSelectDatabases = catalogQuery{
+	name: "databases",
+	variants: []sqlVariant{
+		{90600, `
+select d.datname
+  from pg_database d
+ where not d.datistemplate
+   and d.datallowconn
+ order by d.datname`},
+	},
+}
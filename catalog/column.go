@@ -0,0 +1,146 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// TableColumn contains information about a column of a table.
+type TableColumn struct {
+	tableOID     pgtype.OID // unique identifier of the column's table
+	namespace    string     // namespace of the column's table
+	tableName    string     // name of the column's table
+	name         string     // name of the column
+	typ          string     // type as formatted by format_type(), e.g. "character(2)"
+	typeName     string     // name of the base type, e.g. "bpchar"
+	length       int        // declared max. length of a character type, or -1
+	notNull      bool       // if true, column has a NOT NULL constraint
+	defaultExpr  string     // default value expression; empty if none
+	identity     string     // "a" = always, "d" = by default, empty if not an identity column
+	inPrimaryKey bool       // if true, column is part of the table's primary key
+}
+
+func (c *TableColumn) TableOID() pgtype.OID { return c.tableOID }
+func (c *TableColumn) Namespace() string    { return c.namespace }
+func (c *TableColumn) TableName() string    { return c.tableName }
+func (c *TableColumn) Name() string         { return c.name }
+func (c *TableColumn) Type() string         { return c.typ }
+func (c *TableColumn) TypeName() string     { return c.typeName }
+func (c *TableColumn) Length() int          { return c.length }
+func (c *TableColumn) NotNull() bool        { return c.notNull }
+func (c *TableColumn) Default() string      { return c.defaultExpr }
+func (c *TableColumn) Identity() string     { return c.identity }
+func (c *TableColumn) InPrimaryKey() bool   { return c.inPrimaryKey }
+
+// IsSerial reports whether the column's default takes the next value of a
+// sequence, as a serial or bigserial column's does.
+func (c *TableColumn) IsSerial() bool {
+	return c.identity == "" && strings.HasPrefix(c.defaultExpr, "nextval(")
+}
+
+// QualifiedName returns the column name prefixed by its table's qualified
+// name, e.g. "users.email" or "audit.events.id".
+func (c *TableColumn) QualifiedName() string {
+	return qualify(c.namespace, c.tableName) + "." + c.name
+}
+
+// Columns returns every column of the tables in the DB. Like AllIndexes, the
+// result is cached, and safe for the caller to modify.
+func (db *DB) Columns(ctx context.Context) ([]*TableColumn, error) {
+	if db.stale(db.columnsLoadedAt) {
+		var result []*TableColumn
+		err := db.readOnly(ctx, "columns", func(tx *pgx.Tx) (err error) {
+			result, err = loadColumns(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.columns = result
+		db.columnsLoadedAt = time.Now()
+	}
+	return append([]*TableColumn(nil), db.columns...), nil
+}
+
+// Returns the columns of the tables in the namespace; q.v. DB.Columns.
+func loadColumns(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*TableColumn, error) {
+	sql, err := sqlSelectColumns.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*TableColumn
+	for rows.Next() {
+		var c TableColumn
+		if err := scanColumn(rows, &c); err != nil {
+			return nil, err
+		}
+		columns = append(columns, &c)
+	}
+	return columns, rows.Err()
+}
+
+// Identity columns and partitioning were introduced in Postgres 10. A
+// partition has the same columns as its partitioned table, so only the
+// latter's are returned.
+var sqlSelectColumns = catalogQuery{
+	name: "columns",
+	variants: []sqlVariant{
+		{100000, strings.NewReplacer(
+			"$ATTIDENTITY", "a.attidentity::text",
+			"$PARTITION", "\n   and not c.relispartition",
+		).Replace(sqlSelectColumnsTemplate)},
+		{90600, strings.NewReplacer("$ATTIDENTITY", "''", "$PARTITION", "").Replace(sqlSelectColumnsTemplate)},
+	},
+}
+
+const sqlSelectColumnsTemplate = `
+select c.oid,
+       ns.nspname,
+       c.relname,
+       a.attname,
+       format_type(a.atttypid, a.atttypmod),
+       t.typname,
+       case when t.typname in ('bpchar', 'varchar') and a.atttypmod > 4
+            then a.atttypmod - 4
+            else -1
+       end,
+       a.attnotnull,
+       coalesce(pg_get_expr(d.adbin, d.adrelid), ''),
+       $ATTIDENTITY,
+       coalesce(a.attnum = any(pk.conkey), false)
+  from pg_attribute a
+  join pg_class c on c.oid = a.attrelid
+  join pg_namespace ns on ns.oid = c.relnamespace
+  join pg_type t on t.oid = a.atttypid
+  left outer join pg_attrdef d on d.adrelid = a.attrelid and d.adnum = a.attnum
+  left outer join pg_constraint pk on pk.conrelid = c.oid and pk.contype = 'p'
+ where ns.nspname = $1
+   and c.relkind in ('r', 'p')$PARTITION
+   and a.attnum > 0
+   and not a.attisdropped
+ order by c.relname, a.attnum`
+
+func scanColumn(sc scannable, c *TableColumn) error {
+	return sc.Scan(
+		&c.tableOID,     // pg_class.oid
+		&c.namespace,    // pg_namespace.nspname
+		&c.tableName,    // pg_class.relname
+		&c.name,         // pg_attribute.attname
+		&c.typ,          // format_type(pg_attribute.atttypid, atttypmod)
+		&c.typeName,     // pg_type.typname
+		&c.length,       // pg_attribute.atttypmod - 4, for character types
+		&c.notNull,      // pg_attribute.attnotnull
+		&c.defaultExpr,  // pg_get_expr(pg_attrdef.adbin)
+		&c.identity,     // pg_attribute.attidentity
+		&c.inPrimaryKey, // pg_attribute.attnum = any(pg_constraint.conkey)
+	)
+}
@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// Constraint contains information about a table constraint.
+type Constraint struct {
+	oid         pgtype.OID // unique identifier of the constraint
+	name        string     // name of the constraint
+	namespace   string     // namespace of the constraint's table
+	tableName   string     // name of the constraint's table
+	kind        string     // "c" = check, "f" = foreign key, "p" = primary key, "u" = unique, etc.
+	isValidated bool       // if false, existing rows have not been checked (NOT VALID)
+	definition  string     // as reconstructed by pg_get_constraintdef()
+}
+
+func (c *Constraint) OID() pgtype.OID    { return c.oid }
+func (c *Constraint) Name() string       { return c.name }
+func (c *Constraint) Namespace() string  { return c.namespace }
+func (c *Constraint) TableName() string  { return c.tableName }
+func (c *Constraint) Kind() string       { return c.kind }
+func (c *Constraint) IsValidated() bool  { return c.isValidated }
+func (c *Constraint) Definition() string { return c.definition }
+
+// QualifiedTableName returns the table name prefixed by its namespace. If the
+// namespace is "public", however, it is omitted for brevity.
+func (c *Constraint) QualifiedTableName() string { return qualify(c.namespace, c.tableName) }
+
+// Trigger contains information about a table trigger.
+type Trigger struct {
+	oid          pgtype.OID // unique identifier of the trigger
+	name         string     // name of the trigger
+	namespace    string     // namespace of the trigger's table
+	tableName    string     // name of the trigger's table
+	enabled      string     // "O" = origin and local, "D" = disabled, "R" = replica, "A" = always
+	isInternal   bool       // if true, trigger implements a constraint, e.g. a foreign key
+	functionName string     // name of the trigger function
+}
+
+func (tg *Trigger) OID() pgtype.OID      { return tg.oid }
+func (tg *Trigger) Name() string         { return tg.name }
+func (tg *Trigger) Namespace() string    { return tg.namespace }
+func (tg *Trigger) TableName() string    { return tg.tableName }
+func (tg *Trigger) Enabled() string      { return tg.enabled }
+func (tg *Trigger) IsInternal() bool     { return tg.isInternal }
+func (tg *Trigger) FunctionName() string { return tg.functionName }
+
+// IsDisabled reports whether the trigger never fires.
+func (tg *Trigger) IsDisabled() bool { return tg.enabled == "D" }
+
+// QualifiedTableName returns the table name prefixed by its namespace. If the
+// namespace is "public", however, it is omitted for brevity.
+func (tg *Trigger) QualifiedTableName() string { return qualify(tg.namespace, tg.tableName) }
+
+// Constraints returns every constraint on the tables in the DB. Like
+// AllIndexes, the result is cached, and safe for the caller to modify.
+func (db *DB) Constraints(ctx context.Context) ([]*Constraint, error) {
+	if db.stale(db.constraintsLoadedAt) {
+		var result []*Constraint
+		err := db.readOnly(ctx, "constraints", func(tx *pgx.Tx) (err error) {
+			result, err = loadConstraints(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.constraints = result
+		db.constraintsLoadedAt = time.Now()
+	}
+	return append([]*Constraint(nil), db.constraints...), nil
+}
+
+// Triggers returns every trigger on the tables in the DB, including the
+// internal triggers that implement foreign keys. Like AllIndexes, the result
+// is cached, and safe for the caller to modify.
+func (db *DB) Triggers(ctx context.Context) ([]*Trigger, error) {
+	if db.stale(db.triggersLoadedAt) {
+		var result []*Trigger
+		err := db.readOnly(ctx, "triggers", func(tx *pgx.Tx) (err error) {
+			result, err = loadTriggers(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.triggers = result
+		db.triggersLoadedAt = time.Now()
+	}
+	return append([]*Trigger(nil), db.triggers...), nil
+}
+
+// Returns the constraints on the tables in the namespace; q.v.
+// DB.Constraints.
+func loadConstraints(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Constraint, error) {
+	sql, err := sqlSelectConstraints.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var constraints []*Constraint
+	for rows.Next() {
+		var c Constraint
+		if err := scanConstraint(rows, &c); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, &c)
+	}
+	return constraints, rows.Err()
+}
+
+// Returns the triggers on the tables in the namespace; q.v. DB.Triggers.
+func loadTriggers(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Trigger, error) {
+	sql, err := sqlSelectTriggers.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var triggers []*Trigger
+	for rows.Next() {
+		var tg Trigger
+		if err := scanTrigger(rows, &tg); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, &tg)
+	}
+	return triggers, rows.Err()
+}
+
+var sqlSelectConstraints = catalogQuery{
+	name: "constraints",
+	variants: []sqlVariant{
+		{90600, `
+select c.oid,
+       c.conname,
+       ns.nspname,
+       t.relname,
+       c.contype::text,
+       c.convalidated,
+       pg_get_constraintdef(c.oid)
+  from pg_constraint c
+  join pg_class t on t.oid = c.conrelid
+  join pg_namespace ns on ns.oid = t.relnamespace
+ where ns.nspname = $1`},
+	},
+}
+
+func scanConstraint(sc scannable, c *Constraint) error {
+	return sc.Scan(
+		&c.oid,         // pg_constraint.oid
+		&c.name,        // pg_constraint.conname
+		&c.namespace,   // pg_namespace.nspname
+		&c.tableName,   // pg_class.relname
+		&c.kind,        // pg_constraint.contype
+		&c.isValidated, // pg_constraint.convalidated
+		&c.definition,  // pg_get_constraintdef(pg_constraint.oid)
+	)
+}
+
+var sqlSelectTriggers = catalogQuery{
+	name: "triggers",
+	variants: []sqlVariant{
+		{90600, `
+select tg.oid,
+       tg.tgname,
+       ns.nspname,
+       t.relname,
+       tg.tgenabled::text,
+       tg.tgisinternal,
+       p.proname
+  from pg_trigger tg
+  join pg_class t on t.oid = tg.tgrelid
+  join pg_namespace ns on ns.oid = t.relnamespace
+  join pg_proc p on p.oid = tg.tgfoid
+ where ns.nspname = $1`},
+	},
+}
+
+func scanTrigger(sc scannable, tg *Trigger) error {
+	return sc.Scan(
+		&tg.oid,          // pg_trigger.oid
+		&tg.name,         // pg_trigger.tgname
+		&tg.namespace,    // pg_namespace.nspname
+		&tg.tableName,    // pg_class.relname
+		&tg.enabled,      // pg_trigger.tgenabled
+		&tg.isInternal,   // pg_trigger.tgisinternal
+		&tg.functionName, // pg_proc.proname
+	)
+}
@@ -0,0 +1,509 @@
+// Package catalog loads information about a Postgres database from its system
+// catalogs and statistics views. The central type is Index, which describes
+// an index along with its usage statistics; a DB loads and caches them.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// Source is implemented by anything that can supply a database's indexes,
+// tables, columns, constraints, settings and privileges to the checks: a live
+// DB, or a Snapshot loaded from a file or built in memory. Every method
+// returns a newly allocated slice, which the caller may modify.
+type Source interface {
+	ServerVersion() Version                                  // the server's version; zero if unknown
+	AllIndexes(ctx context.Context) ([]*Index, error)        // valid, live indexes
+	InvalidIndexes(ctx context.Context) ([]*Index, error)    // invalid, not ready or not live indexes
+	EveryIndex(ctx context.Context) ([]*Index, error)        // all of the above
+	AllTables(ctx context.Context) ([]*Table, error)         // tables and their statistics
+	ColumnStats(ctx context.Context) ([]*ColumnStats, error) // planner statistics of analyzed columns
+	ForeignKeys(ctx context.Context) ([]*ForeignKey, error)  // foreign key constraints
+	Columns(ctx context.Context) ([]*TableColumn, error)     // columns of every table
+	Constraints(ctx context.Context) ([]*Constraint, error)  // constraints of every kind
+	Triggers(ctx context.Context) ([]*Trigger, error)        // triggers, including internal ones
+	Settings(ctx context.Context) ([]*Setting, error)        // server configuration parameters
+	Roles(ctx context.Context) ([]*Role, error)              // roles, except the predefined ones
+	PublicGrants(ctx context.Context) ([]*Grant, error)      // privileges granted to PUBLIC
+	Functions(ctx context.Context) ([]*Function, error)      // functions and procedures
+	Policies(ctx context.Context) ([]*Policy, error)         // row-level security policies
+}
+
+// DB exposes a high-level interface to the Postgres information schema.
+type DB struct {
+	pool      *pgx.ConnPool
+	namespace string
+	version   Version
+	ttl       time.Duration                            // how long loaded data is cached; zero means forever
+	onQuery   func(name string, elapsed time.Duration) // called after each catalog query; may be nil
+
+	statementTimeout time.Duration // statement_timeout of each query; zero means none
+	lockTimeout      time.Duration // lock_timeout of each query; zero means none
+
+	indexes        []*Index       // every index in the namespace, valid or not
+	loadedAt       time.Time      // when indexes was loaded
+	tables         []*Table       // every table in the namespace
+	tablesLoadedAt time.Time      // when tables was loaded
+	stats          []*ColumnStats // statistics of every analyzed column
+	statsLoadedAt  time.Time      // when stats was loaded
+	fkeys          []*ForeignKey  // every foreign key in the namespace
+	fkeysLoadedAt  time.Time      // when fkeys was loaded
+
+	columns         []*TableColumn // every column of every table in the namespace
+	columnsLoadedAt time.Time      // when columns was loaded
+
+	constraints         []*Constraint // every constraint in the namespace
+	constraintsLoadedAt time.Time     // when constraints was loaded
+	triggers            []*Trigger    // every trigger in the namespace
+	triggersLoadedAt    time.Time     // when triggers was loaded
+
+	settings         []*Setting // the server's configuration parameters
+	settingsLoadedAt time.Time  // when settings was loaded
+
+	roles             []*Role     // every role in the cluster
+	rolesLoadedAt     time.Time   // when roles was loaded
+	grants            []*Grant    // privileges granted to PUBLIC
+	grantsLoadedAt    time.Time   // when grants was loaded
+	functions         []*Function // every function in the namespace
+	functionsLoadedAt time.Time   // when functions was loaded
+
+	policies         []*Policy // every row-level security policy in the namespace
+	policiesLoadedAt time.Time // when policies was loaded
+}
+
+// New creates a DB that reads the given namespace (schema) through the
+// connections of pool. It fails if the server's version cannot be determined
+// or is older than MinVersion.
+func New(ctx context.Context, pool *pgx.ConnPool, namespace string) (*DB, error) {
+	db := &DB{pool: pool, namespace: namespace}
+	err := db.readOnly(ctx, "server version", func(tx *pgx.Tx) (err error) {
+		db.version, err = queryServerVersion(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if db.version < MinVersion {
+		return nil, &UnsupportedError{What: "pglint", Version: db.version, MinVersion: MinVersion}
+	}
+	return db, nil
+}
+
+// Namespace reports the name of the schema that db reads.
+func (db *DB) Namespace() string { return db.namespace }
+
+// ServerVersion reports the version of the connected server.
+func (db *DB) ServerVersion() Version { return db.version }
+
+// SetCacheTTL makes db reload its catalog data once it is older than ttl. By
+// default, data is loaded once and cached for the lifetime of the DB, which
+// suits a one-off report but not a long-running process.
+func (db *DB) SetCacheTTL(ttl time.Duration) { db.ttl = ttl }
+
+// SetQueryLogger makes db call f with the name and duration of each catalog
+// query it runs. Since Load runs queries concurrently, f must be safe for
+// concurrent use.
+func (db *DB) SetQueryLogger(f func(name string, elapsed time.Duration)) { db.onQuery = f }
+
+// SetTimeouts sets the statement_timeout and lock_timeout under which db runs
+// each catalog query, so that a slow query or one stuck behind a lock on a
+// busy server fails instead of holding up the caller. Zero disables a timeout.
+func (db *DB) SetTimeouts(statement, lock time.Duration) {
+	db.statementTimeout = statement
+	db.lockTimeout = lock
+}
+
+// Reports the time elapsed since start to the query logger, if any.
+func (db *DB) logQuery(name string, start time.Time) {
+	if db.onQuery != nil {
+		db.onQuery(name, time.Since(start))
+	}
+}
+
+// Load loads every kind of catalog data that the Source methods return, or
+// reloads it if the cache has expired, running the queries concurrently on
+// separate connections of db's pool. Calling Load is optional, since each
+// method loads its own data on first use, one query at a time; it returns
+// the first error encountered. If ctx is canceled, the data loaded so far
+// stays cached. Load must not be called concurrently with any other method
+// of db.
+func (db *DB) Load(ctx context.Context) error {
+	loaders := []func() error{
+		func() error { _, err := db.EveryIndex(ctx); return err },
+		func() error { _, err := db.AllTables(ctx); return err },
+		func() error { _, err := db.ColumnStats(ctx); return err },
+		func() error { _, err := db.ForeignKeys(ctx); return err },
+		func() error { _, err := db.Columns(ctx); return err },
+		func() error { _, err := db.Constraints(ctx); return err },
+		func() error { _, err := db.Triggers(ctx); return err },
+		func() error { _, err := db.Settings(ctx); return err },
+		func() error { _, err := db.Roles(ctx); return err },
+		func() error { _, err := db.PublicGrants(ctx); return err },
+		func() error { _, err := db.Functions(ctx); return err },
+		func() error { _, err := db.Policies(ctx); return err },
+	}
+	errs := make(chan error, len(loaders))
+	for _, load := range loaders {
+		go func(load func() error) { errs <- load() }(load)
+	}
+	var first error
+	for range loaders {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Runs f in a read-only transaction on one of db's connections, with db's
+// timeouts in effect, and reports its duration to the query logger under the
+// given name. The transaction is always rolled back. If ctx is canceled while
+// a query is running, the server is asked to cancel it.
+func (db *DB) readOnly(ctx context.Context, name string, f func(tx *pgx.Tx) error) error {
+	defer db.logQuery(name, time.Now())
+	tx, err := db.pool.BeginEx(ctx, &pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecEx(ctx, sqlSetTimeouts, nil, millis(db.statementTimeout), millis(db.lockTimeout))
+	if err != nil {
+		return fmt.Errorf("setting timeouts: %v", err)
+	}
+	return f(tx)
+}
+
+// Sets the timeouts for the current transaction only.
+const sqlSetTimeouts = `
+select set_config('statement_timeout', $1, true),
+       set_config('lock_timeout', $2, true)`
+
+// Formats d as a number of milliseconds, the default unit of the timeout
+// settings. A nonzero duration is rounded up, since zero disables a timeout.
+func millis(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Millisecond-1)/time.Millisecond), 10)
+}
+
+// LoadedAt reports when the cached indexes were loaded, or the zero time if
+// they have not been loaded yet.
+func (db *DB) LoadedAt() time.Time { return db.loadedAt }
+
+// Reports whether cached catalog data loaded at the given time is missing or
+// has expired.
+func (db *DB) stale(loadedAt time.Time) bool {
+	return loadedAt.IsZero() || (db.ttl > 0 && time.Since(loadedAt) >= db.ttl)
+}
+
+// AllIndexes returns all valid, live indexes in the DB. The result is cached,
+// but every call returns a unique slice, so it is safe for the caller to
+// modify.
+func (db *DB) AllIndexes(ctx context.Context) ([]*Index, error) {
+	return db.selectIndexes(ctx, (*Index).Usable)
+}
+
+// InvalidIndexes returns the indexes in the DB that are invalid, not ready or
+// not live, e.g. the remains of a failed CREATE INDEX CONCURRENTLY. Like
+// AllIndexes, the result is safe for the caller to modify.
+func (db *DB) InvalidIndexes(ctx context.Context) ([]*Index, error) {
+	return db.selectIndexes(ctx, func(ind *Index) bool { return !ind.Usable() })
+}
+
+// EveryIndex returns every index in the DB, valid or not.
+func (db *DB) EveryIndex(ctx context.Context) ([]*Index, error) {
+	return db.selectIndexes(ctx, func(*Index) bool { return true })
+}
+
+// Loads every index in the DB on first use, or when the cache has expired,
+// then returns the cached indexes for which pred returns true in a newly
+// allocated slice.
+func (db *DB) selectIndexes(ctx context.Context, pred func(*Index) bool) ([]*Index, error) {
+	if db.stale(db.loadedAt) {
+		var result []*Index
+		err := db.readOnly(ctx, "indexes", func(tx *pgx.Tx) (err error) {
+			result, err = loadIndexes(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.indexes = result
+		db.loadedAt = time.Now()
+	}
+	return FilterIndexes(db.indexes, pred), nil
+}
+
+// Returns all indexes in the database, including invalid ones; q.v.
+// DB.AllIndexes and DB.InvalidIndexes.
+func loadIndexes(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Index, error) {
+	// Fetch the basic index data.
+	sql, err := sqlSelectIndexInfo.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []*Index
+	for rows.Next() {
+		var index Index
+		if err := scanIndex(rows, &index); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, &index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// For each index, set its column attributes. Each attribute must either be
+	// a column reference or an expression.
+	//
+	// We do so here for efficiency's sake; we can retrieve all of the column
+	// names in a single query round trip. (The index expressions are stored
+	// with the rest of the index metadata.)
+
+	tableCols, err := loadIndexTableColumns(ctx, tx, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		attrs := make([]string, len(index.keys))
+		exprs := splitExprs(index.Exprs())
+		for i, key := range index.keys {
+			switch {
+			case key == 0:
+				if len(exprs) == 0 {
+					return nil, fmt.Errorf("index %q (%d): no expression for key #%d (value:0)",
+						index.Name(), index.OID(), i)
+				}
+				attrs[i] = exprs[0]
+				exprs = exprs[1:]
+			case key > 0:
+				if name, ok := tableCols[index.tableOID].lookup(int(key)); ok {
+					attrs[i] = name
+				} else {
+					return nil, fmt.Errorf("index %q (%d): no column ref found for key %d (%d)",
+						index.Name(), index.OID(), i, key)
+				}
+			}
+		}
+		index.attrs = attrs
+	}
+
+	// All done.
+	return indexes, nil
+}
+
+// N.B. includes non-live and invalid indexes; callers must filter them. Before
+// Postgres 11, which introduced INCLUDE columns, every column is a key column.
+var sqlSelectIndexInfo = catalogQuery{
+	name: "index info",
+	variants: []sqlVariant{
+		{110000, strings.Replace(sqlSelectIndexInfoTemplate, "$INDNKEYATTS", "i.indnkeyatts", 1)},
+		{90600, strings.Replace(sqlSelectIndexInfoTemplate, "$INDNKEYATTS", "i.indnatts", 1)},
+	},
+}
+
+const sqlSelectIndexInfoTemplate = `
+select c.oid,
+       c.relname,
+       c.relnamespace,
+       ns.nspname,
+       i.indrelid,
+       t.relname,
+       i.indnatts,
+       $INDNKEYATTS,
+       i.indisunique,
+       i.indisprimary,
+       i.indisvalid,
+       i.indisready,
+       i.indislive,
+       i.indkey,
+       i.indcollation,
+       i.indclass,
+       i.indoption,
+       coalesce(pg_get_expr(i.indexprs, i.indrelid), ''),
+       coalesce(pg_get_expr(i.indpred, i.indrelid), ''),
+       t.relpages,
+       t.reltuples::bigint,
+       c.relpages,
+       c.reltuples::bigint,
+       coalesce(s.idx_scan, 0),
+       coalesce(s.idx_tup_read, 0),
+       coalesce(s.idx_tup_fetch, 0),
+       (select pg_relation_size(c.oid)),
+       pg_get_indexdef(c.oid),
+       coalesce((select inh.inhparent
+                   from pg_inherits inh
+                  where inh.inhrelid = c.oid), 0)
+  from pg_index i
+  join pg_class c on c.oid = i.indexrelid
+  join pg_class t on t.oid = i.indrelid
+  join pg_namespace ns on ns.oid = c.relnamespace
+  left outer join pg_stat_user_indexes s on s.indexrelid = i.indexrelid
+ where ns.nspname = $1`
+
+func scanIndex(sc scannable, v *Index) error {
+	return sc.Scan(
+		&v.oid,              // pg_class.oid
+		&v.name,             // pg_class.relname
+		&v.namespaceOID,     // pg_class.relnamespace
+		&v.namespace,        // pg_namespace.nspname
+		&v.tableOID,         // pg_index.indrelid
+		&v.tableName,        // pg_class[2].relname (table)
+		&v.numColumns,       // pg_index.indnatts
+		&v.numKeyColumns,    // pg_index.indnkeyatts
+		&v.isUnique,         // pg_index.indisunique
+		&v.isPrimary,        // pg_index.indisprimary
+		&v.isValid,          // pg_index.indisvalid
+		&v.isReady,          // pg_index.indisready
+		&v.isLive,           // pg_index.indislive
+		&v.keys,             // pg_index.indkey
+		&v.collations,       // pg_index.indcollation
+		&v.classes,          // pg_index.indclass
+		&v.options,          // pg_index.indoption
+		&v.exprs,            // pg_get_expr(pg_index.indexprs)
+		&v.pred,             // pg_get_expr(pg_index.indpred)
+		&v.numTablePages,    // pg_class[2].relpages (table)
+		&v.numTableRows,     // pg_class[2].reltuples (table)
+		&v.numPages,         // pg_class.relpages
+		&v.numRows,          // pg_class.reltuples
+		&v.numScans,         // pg_stat_user_indexes.idx_scan
+		&v.numTuplesRead,    // pg_stat_user_indexes.idx_tup_read
+		&v.numTuplesFetched, // pg_stat_user_indexes.idx_tup_fetch
+		&v.size,             // pg_relation_size(pg_class.oid)
+		&v.definition,       // pg_get_indexdef(pg_class.oid)
+		&v.parentOID,        // pg_inherits.inhparent
+	)
+}
+
+// Reads per-table column information from the connection and organizes it as a
+// mapping from table OID to column list; q.v. type tableCols.
+func loadIndexTableColumns(ctx context.Context, tx *pgx.Tx, version Version) (map[pgtype.OID]*tableCols, error) {
+	sql, err := sqlSelectIndexTableColumnNames.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tc := make(map[pgtype.OID]*tableCols)
+	for rows.Next() {
+		var (
+			id   pgtype.OID // table OID
+			name string     // column name
+			key  int        // column offset
+		)
+		if err := rows.Scan(&id, &name, &key); err != nil {
+			return nil, err
+		}
+		tc[id] = tc[id].add(name, key)
+	}
+	return tc, nil
+}
+
+// Selects column attributes for all tables having at least one index.
+var sqlSelectIndexTableColumnNames = catalogQuery{
+	name: "index table columns",
+	variants: []sqlVariant{
+		{90600, `
+select c.oid, a.attname, a.attnum
+  from pg_class c
+  join pg_attribute a on a.attrelid = c.oid
+ where c.oid in (select indrelid from pg_index)
+   and a.attnum >= 1`},
+	},
+}
+
+type (
+	// Associates column number and name.
+	colNameKey struct {
+		name string
+		key  int // 1-based
+	}
+
+	// Stores the column names/offsets for a table.
+	tableCols struct {
+		columns []colNameKey
+	}
+)
+
+// Adds a column to the table. Works if t if nil: allocates on demand and
+// returns the new struct value.
+func (t *tableCols) add(name string, key int) *tableCols {
+	if t == nil {
+		t = new(tableCols)
+	}
+	t.columns = append(t.columns, colNameKey{name, key})
+	return t
+}
+
+// Reports the column name associated with the key.
+func (t *tableCols) lookup(key int) (name string, found bool) {
+	if t != nil {
+		for _, c := range t.columns {
+			if c.key == key {
+				return c.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Naively parses the output of pg_get_expr().
+// E.g. "f(x, y), z, a + b" -> ["f(x, y)", "z", "a + b"]
+//
+// Commas and parentheses within string literals and quoted identifiers are
+// ignored. (A doubled quote character, which escapes the quote, is treated as
+// the end of one quoted string and the start of another, with the same result.)
+func splitExprs(input string) []string {
+	var (
+		exprs []string // the result
+		curr  []rune   // current sub-expression
+		nest  = 0      // current parens nesting
+		quote rune     // current quote character, or 0 if not quoted
+	)
+	for _, c := range input {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			curr = append(curr, c)
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(':
+			nest++
+		case ')':
+			nest--
+		case ' ':
+			if len(curr) == 0 {
+				continue
+			}
+		case ',':
+			if nest == 0 {
+				exprs = append(exprs, string(curr))
+				curr = curr[:0]
+				continue // skip
+			}
+		}
+		curr = append(curr, c)
+	}
+	if len(curr) > 0 {
+		exprs = append(exprs, string(curr))
+	}
+	return exprs
+}
@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitExprs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"x", []string{"x"}},
+		{"f(x, y), z, a + b", []string{"f(x, y)", "z", "a + b"}},
+		{"  lower(email)", []string{"lower(email)"}},
+		{"f(g(x, y), h(z)), w", []string{"f(g(x, y), h(z))", "w"}},
+		{"COALESCE(a, ','::text), b", []string{"COALESCE(a, ','::text)", "b"}},
+		{"(a = ')'), b", []string{"(a = ')')", "b"}},
+		{`"weird, name", b`, []string{`"weird, name"`, "b"}},
+		{"replace(x, 'it''s', ''), y", []string{"replace(x, 'it''s', '')", "y"}},
+	}
+	for _, tt := range tests {
+		if got := splitExprs(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitExprs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTableColsLookup(t *testing.T) {
+	var tc *tableCols
+	if _, ok := tc.lookup(1); ok {
+		t.Error("lookup in nil tableCols succeeded")
+	}
+	tc = tc.add("id", 1).add("email", 3)
+	if name, ok := tc.lookup(3); !ok || name != "email" {
+		t.Errorf("lookup(3) = %q, %v; want \"email\", true", name, ok)
+	}
+	if _, ok := tc.lookup(2); ok {
+		t.Error("lookup of dropped column succeeded")
+	}
+}
+
+func TestMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0"},
+		{time.Microsecond, "1"},
+		{time.Millisecond, "1"},
+		{1500 * time.Microsecond, "2"},
+		{30 * time.Second, "30000"},
+	}
+	for _, tt := range tests {
+		if got := millis(tt.d); got != tt.want {
+			t.Errorf("millis(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
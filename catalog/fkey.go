@@ -0,0 +1,179 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// ForeignKey contains information about a foreign key constraint.
+type ForeignKey struct {
+	oid          pgtype.OID  // unique identifier of the constraint
+	name         string      // name of the constraint
+	namespace    string      // namespace of the referencing table
+	tableName    string      // name of the referencing table
+	refNamespace string      // namespace of the referenced table
+	refTableName string      // name of the referenced table
+	matchType    string      // "f" = full, "p" = partial, "s" = simple
+	columns      []FKColumns // the referencing and referenced columns, in order
+}
+
+// FKColumns pairs a referencing column of a foreign key with the column it
+// references.
+type FKColumns struct {
+	Column, RefColumn Column
+}
+
+// Column describes a table column.
+type Column struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`                // as formatted by format_type()
+	Collation string `json:"collation,omitempty"` // empty if not collatable
+	NotNull   bool   `json:"not_null"`
+}
+
+func (fk *ForeignKey) OID() pgtype.OID      { return fk.oid }
+func (fk *ForeignKey) Name() string         { return fk.name }
+func (fk *ForeignKey) Namespace() string    { return fk.namespace }
+func (fk *ForeignKey) TableName() string    { return fk.tableName }
+func (fk *ForeignKey) RefNamespace() string { return fk.refNamespace }
+func (fk *ForeignKey) RefTableName() string { return fk.refTableName }
+func (fk *ForeignKey) MatchType() string    { return fk.matchType }
+func (fk *ForeignKey) Columns() []FKColumns { return fk.columns }
+
+// IsMatchSimple reports whether the foreign key is MATCH SIMPLE, the default,
+// under which a row is not checked if any of its referencing columns is null.
+func (fk *ForeignKey) IsMatchSimple() bool { return fk.matchType == "s" }
+
+// QualifiedTableName returns the referencing table's name prefixed by its
+// namespace. If the namespace is "public", however, it is omitted for brevity.
+func (fk *ForeignKey) QualifiedTableName() string { return qualify(fk.namespace, fk.tableName) }
+
+// QualifiedRefTableName is like QualifiedTableName but for the referenced
+// table.
+func (fk *ForeignKey) QualifiedRefTableName() string {
+	return qualify(fk.refNamespace, fk.refTableName)
+}
+
+// Prefixes name with namespace, unless namespace is "public".
+func qualify(namespace, name string) string {
+	if namespace == "public" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// ForeignKeys returns every foreign key constraint on the tables in the DB.
+// Like AllIndexes, the result is cached, and safe for the caller to modify.
+func (db *DB) ForeignKeys(ctx context.Context) ([]*ForeignKey, error) {
+	if db.stale(db.fkeysLoadedAt) {
+		var result []*ForeignKey
+		err := db.readOnly(ctx, "foreign keys", func(tx *pgx.Tx) (err error) {
+			result, err = loadForeignKeys(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.fkeys = result
+		db.fkeysLoadedAt = time.Now()
+	}
+	return append([]*ForeignKey(nil), db.fkeys...), nil
+}
+
+// Returns the foreign keys on the tables in the namespace; q.v.
+// DB.ForeignKeys. The query returns one row per column, ordered by constraint.
+func loadForeignKeys(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*ForeignKey, error) {
+	sql, err := sqlSelectForeignKeys.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fkeys []*ForeignKey
+	for rows.Next() {
+		var (
+			fk   ForeignKey
+			cols FKColumns
+		)
+		if err := scanForeignKey(rows, &fk, &cols); err != nil {
+			return nil, err
+		}
+		if n := len(fkeys); n > 0 && fkeys[n-1].oid == fk.oid {
+			fkeys[n-1].columns = append(fkeys[n-1].columns, cols)
+			continue
+		}
+		fk.columns = []FKColumns{cols}
+		fkeys = append(fkeys, &fk)
+	}
+	return fkeys, rows.Err()
+}
+
+// From Postgres 11, a foreign key on a partitioned table is cloned into each of
+// its partitions, and from Postgres 12, so is one referencing a partitioned
+// table, into each referenced partition. The clones, whose conparentid is the
+// constraint they were cloned from, are omitted: they have the same columns,
+// and names the user never chose.
+var sqlSelectForeignKeys = catalogQuery{
+	name: "foreign keys",
+	variants: []sqlVariant{
+		{110000, strings.Replace(sqlSelectForeignKeysTemplate, "$CLONE", "\n   and c.conparentid = 0", 1)},
+		{90600, strings.Replace(sqlSelectForeignKeysTemplate, "$CLONE", "", 1)},
+	},
+}
+
+const sqlSelectForeignKeysTemplate = `
+select c.oid,
+       c.conname,
+       ns.nspname,
+       t.relname,
+       rns.nspname,
+       r.relname,
+       c.confmatchtype::text,
+       a.attname,
+       format_type(a.atttypid, a.atttypmod),
+       coalesce(co.collname, ''),
+       a.attnotnull,
+       ra.attname,
+       format_type(ra.atttypid, ra.atttypmod),
+       coalesce(rco.collname, ''),
+       ra.attnotnull
+  from pg_constraint c
+  join pg_class t on t.oid = c.conrelid
+  join pg_namespace ns on ns.oid = t.relnamespace
+  join pg_class r on r.oid = c.confrelid
+  join pg_namespace rns on rns.oid = r.relnamespace
+ cross join lateral unnest(c.conkey, c.confkey) with ordinality as k(attnum, refattnum, n)
+  join pg_attribute a on a.attrelid = c.conrelid and a.attnum = k.attnum
+  join pg_attribute ra on ra.attrelid = c.confrelid and ra.attnum = k.refattnum
+  left outer join pg_collation co on co.oid = a.attcollation
+  left outer join pg_collation rco on rco.oid = ra.attcollation
+ where c.contype = 'f'
+   and ns.nspname = $1$CLONE
+ order by c.oid, k.n`
+
+func scanForeignKey(sc scannable, fk *ForeignKey, cols *FKColumns) error {
+	return sc.Scan(
+		&fk.oid,                   // pg_constraint.oid
+		&fk.name,                  // pg_constraint.conname
+		&fk.namespace,             // pg_namespace.nspname
+		&fk.tableName,             // pg_class.relname
+		&fk.refNamespace,          // pg_namespace[2].nspname (referenced)
+		&fk.refTableName,          // pg_class[2].relname (referenced)
+		&fk.matchType,             // pg_constraint.confmatchtype
+		&cols.Column.Name,         // pg_attribute.attname
+		&cols.Column.Type,         // format_type(pg_attribute.atttypid, atttypmod)
+		&cols.Column.Collation,    // pg_collation.collname
+		&cols.Column.NotNull,      // pg_attribute.attnotnull
+		&cols.RefColumn.Name,      // pg_attribute[2].attname (referenced)
+		&cols.RefColumn.Type,      // format_type(...) (referenced)
+		&cols.RefColumn.Collation, // pg_collation[2].collname (referenced)
+		&cols.RefColumn.NotNull,   // pg_attribute[2].attnotnull (referenced)
+	)
+}
@@ -1,27 +1,27 @@
-package main
+package catalog
 
 import (
 	"github.com/jackc/pgx/pgtype"
 )
 
-// Categorizes an index based on uniqueness.
-type indexKind string
+// IndexKind categorizes an index based on uniqueness.
+type IndexKind string
 
 const (
-	uniqueIndex    indexKind = "U"
-	nonUniqueIndex indexKind = "N"
-	primaryKey     indexKind = "P"
+	UniqueIndex    IndexKind = "U"
+	NonUniqueIndex IndexKind = "N"
+	PrimaryKey     IndexKind = "P"
 )
 
-// Kind reports the indexKind of v.
-func (v *Index) Kind() indexKind {
+// Kind reports the IndexKind of v.
+func (v *Index) Kind() IndexKind {
 	switch {
 	case v.IsPrimary():
-		return primaryKey
+		return PrimaryKey
 	case v.IsUnique():
-		return uniqueIndex
+		return UniqueIndex
 	}
-	return nonUniqueIndex
+	return NonUniqueIndex
 }
 
 // Index contains information about a PostgreSQL index.
@@ -33,14 +33,16 @@ type Index struct {
 	tableOID         pgtype.OID // unique identifier of the index's table
 	tableName        string     // name of the index's table
 	numColumns       int        // count of columns in the index
+	numKeyColumns    int        // count of key columns; excludes INCLUDE columns
 	isUnique         bool       // if true, index is unique
 	isPrimary        bool       // if true, index represents table PK; IsUnique also true
 	isValid          bool       // if true, currently valid for queries
+	isReady          bool       // if true, ready for inserts
 	isLive           bool       // if false, index is being dropped and should be ignored
-	keys             int2Vector // ordered list of column positions (1..N); 0 = expr
-	collations       oidVector  // for each column, indicates collation used in index
-	classes          oidVector  // for each column, indicates pg_opclass
-	options          oidVector  // for each column, contains flag bits
+	keys             Int2Vector // ordered list of column positions (1..N); 0 = expr
+	collations       OIDVector  // for each column, indicates collation used in index
+	classes          OIDVector  // for each column, indicates pg_opclass
+	options          OIDVector  // for each column, contains flag bits
 	exprs            string     // computed expressions, one for each 0 in Keys
 	pred             string     // partial index predicate; null if not partial index
 	definition       *string    // reconstructed CREATE INDEX statement
@@ -52,8 +54,10 @@ type Index struct {
 	numTuplesRead    int        // count of tuples read from index
 	numTuplesFetched int        // count of tuples fetched from index
 	size             Bytes      // total size of index on disk
+	parentOID        pgtype.OID // partitioned index to which this index is attached; 0 if none
 
-	attrs []string
+	attrs         []string
+	numPartitions int // count of partition indexes rolled up into this one; q.v. RollUpPartitions
 }
 
 func (v *Index) OID() pgtype.OID          { return v.oid }
@@ -63,14 +67,16 @@ func (v *Index) Namespace() string        { return v.namespace }
 func (v *Index) TableOID() pgtype.OID     { return v.tableOID }
 func (v *Index) TableName() string        { return v.tableName }
 func (v *Index) NumColumns() int          { return v.numColumns }
+func (v *Index) NumKeyColumns() int       { return v.numKeyColumns }
 func (v *Index) IsUnique() bool           { return v.isUnique }
 func (v *Index) IsPrimary() bool          { return v.isPrimary }
 func (v *Index) IsValid() bool            { return v.isValid }
+func (v *Index) IsReady() bool            { return v.isReady }
 func (v *Index) IsLive() bool             { return v.isLive }
-func (v *Index) Keys() int2Vector         { return v.keys }
-func (v *Index) Collations() oidVector    { return v.collations }
-func (v *Index) Classes() oidVector       { return v.classes }
-func (v *Index) Options() oidVector       { return v.options }
+func (v *Index) Keys() Int2Vector         { return v.keys }
+func (v *Index) Collations() OIDVector    { return v.collations }
+func (v *Index) Classes() OIDVector       { return v.classes }
+func (v *Index) Options() OIDVector       { return v.options }
 func (v *Index) Exprs() string            { return v.exprs }
 func (v *Index) Pred() string             { return v.pred }
 func (v *Index) Definition() string       { return strVal(v.definition) }
@@ -82,10 +88,22 @@ func (v *Index) NumScans() int            { return v.numScans }
 func (v *Index) NumTuplesRead() int       { return v.numTuplesRead }
 func (v *Index) NumTuplesFetched() int    { return v.numTuplesFetched }
 func (v *Index) Size() Bytes              { return v.size }
+func (v *Index) ParentOID() pgtype.OID    { return v.parentOID }
+func (v *Index) NumPartitions() int       { return v.numPartitions }
 
 // Attrs returns the indexed fields, which may be column names or expressions.
+// They include the columns of an INCLUDE clause, which come last.
 func (v *Index) Attrs() []string { return v.attrs }
 
+// KeyAttrs returns the key fields of the index: Attrs without the columns of
+// an INCLUDE clause, which can be returned by the index but not searched.
+func (v *Index) KeyAttrs() []string {
+	if v.numKeyColumns > 0 && v.numKeyColumns < len(v.attrs) {
+		return v.attrs[:v.numKeyColumns]
+	}
+	return v.attrs
+}
+
 // QualifiedTableName returns the table name prefixed by its namespace. If the
 // namespace is "public", however, it is omitted for brevity.
 func (v *Index) QualifiedTableName() string {
@@ -99,11 +117,31 @@ func (v *Index) QualifiedTableName() string {
 // namespace is "public", however, it is omitted for brevity.
 func (v *Index) QualifiedName() string {
 	if v.namespace == "public" {
-		return v.tableName
+		return v.name
 	}
 	return v.namespace + "." + v.name
 }
 
+// Usable reports whether the index is valid, ready and live, i.e. whether
+// queries can use it.
+func (v *Index) Usable() bool {
+	return v.isValid && v.isReady && v.isLive
+}
+
+// State describes the index's readiness: "valid", "invalid", "not ready" or
+// "not live". Only valid indexes are usable.
+func (v *Index) State() string {
+	switch {
+	case !v.isLive:
+		return "not live"
+	case !v.isReady:
+		return "not ready"
+	case !v.isValid:
+		return "invalid"
+	}
+	return "valid"
+}
+
 // EquivalentTo reports whether v is a structurally equivalent index to u.
 func (v *Index) EquivalentTo(u *Index) bool {
 	if v.OID() == u.OID() {
@@ -111,20 +149,55 @@ func (v *Index) EquivalentTo(u *Index) bool {
 	}
 	return (v.TableOID() == u.TableOID() &&
 		v.IsUnique() == u.IsUnique() &&
-		v.Keys().equal(u.Keys()) &&
-		v.Collations().equal(u.Collations()) &&
-		v.Classes().equal(u.Classes()) &&
-		v.Options().equal(u.Options()) &&
+		v.Keys().Equal(u.Keys()) &&
+		v.Collations().Equal(u.Collations()) &&
+		v.Classes().Equal(u.Classes()) &&
+		v.Options().Equal(u.Options()) &&
 		v.Exprs() == u.Exprs() &&
 		v.Pred() == u.Pred())
 }
 
-// Sorts indexes lexicographically by name.
-type indexesByName []*Index
+// EquivalentAcross reports whether v is structurally equivalent to u, where u
+// may belong to a different database. Since OIDs of user objects differ
+// between databases, tables are matched by qualified name and keys by the
+// indexed columns and expressions rather than by column position. (Collation
+// and operator class OIDs are still compared directly; that is reliable for
+// the built-in ones, which are the norm.)
+func (v *Index) EquivalentAcross(u *Index) bool {
+	return (v.Namespace() == u.Namespace() &&
+		v.TableName() == u.TableName() &&
+		v.IsUnique() == u.IsUnique() &&
+		stringsEqual(v.Attrs(), u.Attrs()) &&
+		v.Collations().Equal(u.Collations()) &&
+		v.Classes().Equal(u.Classes()) &&
+		v.Options().Equal(u.Options()) &&
+		v.Pred() == u.Pred())
+}
 
-func (a indexesByName) Len() int           { return len(a) }
-func (a indexesByName) Less(i, j int) bool { return a[i].Name() > a[j].Name() }
-func (a indexesByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+// FilterIndexes returns the values in xs for which pred returns true.
+func FilterIndexes(xs []*Index, pred func(*Index) bool) []*Index {
+	var answer []*Index
+	for _, x := range xs {
+		if pred(x) {
+			answer = append(answer, x)
+		}
+	}
+	return answer
+}
+
+// stringsEqual reports whether a and b contain the same strings in the same
+// order.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, s := range a {
+		if s != b[i] {
+			return false
+		}
+	}
+	return true
+}
 
 // strVal returns an empty string is s is nil, *s otherwise.
 func strVal(s *string) string {
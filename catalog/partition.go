@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"github.com/jackc/pgx/pgtype"
+)
+
+// RollUpPartitions returns indexes with the index of each partition folded
+// into the partitioned index it is attached to, at the top of the partition
+// hierarchy, so that a partitioned index appears once rather than once per
+// partition. The statistics and size of a rolled-up index are the totals of
+// its partitions' indexes, since those are what dropping it would reclaim. An
+// index whose partitioned parent is not among indexes, e.g. because it is
+// invalid, is left alone. Indexes are not modified; rolled-up indexes are
+// copies.
+func RollUpPartitions(indexes []*Index) []*Index {
+	byOID := make(map[pgtype.OID]*Index, len(indexes))
+	for _, ind := range indexes {
+		byOID[ind.oid] = ind
+	}
+	root := func(ind *Index) *Index {
+		for {
+			parent, ok := byOID[ind.parentOID]
+			if ind.parentOID == 0 || !ok {
+				return ind
+			}
+			ind = parent
+		}
+	}
+	rollups := make(map[pgtype.OID]*Index)
+	for _, ind := range indexes {
+		r := root(ind)
+		if r == ind {
+			continue
+		}
+		sum, ok := rollups[r.oid]
+		if !ok {
+			c := *r
+			sum = &c
+			rollups[r.oid] = sum
+		}
+		sum.numPartitions++
+		sum.numPages += ind.numPages
+		sum.numRows += ind.numRows
+		sum.numTablePages += ind.numTablePages
+		sum.numTableRows += ind.numTableRows
+		sum.numScans += ind.numScans
+		sum.numTuplesRead += ind.numTuplesRead
+		sum.numTuplesFetched += ind.numTuplesFetched
+		sum.size += ind.size
+	}
+	var answer []*Index
+	for _, ind := range indexes {
+		if sum, ok := rollups[ind.oid]; ok {
+			answer = append(answer, sum)
+		} else if root(ind) == ind {
+			answer = append(answer, ind)
+		}
+	}
+	return answer
+}
+
+// PartitionRoots maps the OID of each of the tables to that of the table at
+// the top of its partition hierarchy: the table itself, unless it is a
+// partition whose partitioned parent is among tables.
+func PartitionRoots(tables []*Table) map[pgtype.OID]pgtype.OID {
+	byOID := TablesByOID(tables)
+	roots := make(map[pgtype.OID]pgtype.OID, len(tables))
+	for _, t := range tables {
+		r := t
+		for r.parentOID != 0 && byOID[r.parentOID] != nil {
+			r = byOID[r.parentOID]
+		}
+		roots[t.oid] = r.oid
+	}
+	return roots
+}
+
+// RollUpTables returns tables with each partition folded into the partitioned
+// table at the top of its hierarchy, as RollUpPartitions does for indexes.
+// The activity and size of a rolled-up table are its own plus those of its
+// partitions, since a partitioned table's activity is recorded against its
+// partitions. Tables are not modified; rolled-up tables are copies.
+func RollUpTables(tables []*Table) []*Table {
+	roots := PartitionRoots(tables)
+	rollups := make(map[pgtype.OID]*Table)
+	byOID := TablesByOID(tables)
+	for _, t := range tables {
+		root := roots[t.oid]
+		if root == t.oid {
+			continue
+		}
+		sum, ok := rollups[root]
+		if !ok {
+			c := *byOID[root]
+			sum = &c
+			rollups[root] = sum
+		}
+		sum.numInserts += t.numInserts
+		sum.numUpdates += t.numUpdates
+		sum.numHotUpdates += t.numHotUpdates
+		sum.numDeletes += t.numDeletes
+		sum.numLiveRows += t.numLiveRows
+		sum.heapSize += t.heapSize
+		sum.totalSize += t.totalSize
+	}
+	var answer []*Table
+	for _, t := range tables {
+		if sum, ok := rollups[t.oid]; ok {
+			answer = append(answer, sum)
+		} else if roots[t.oid] == t.oid {
+			answer = append(answer, t)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,120 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// Policy contains information about a row-level security policy.
+type Policy struct {
+	oid        pgtype.OID // unique identifier of the policy
+	name       string     // name of the policy
+	namespace  string     // namespace of the policy's table
+	tableName  string     // name of the policy's table
+	command    string     // "r" = SELECT, "a" = INSERT, "w" = UPDATE, "d" = DELETE, "*" = all
+	permissive bool       // if false, the policy is restrictive
+	roles      []string   // roles to which the policy applies; "public" for all
+}
+
+func (p *Policy) OID() pgtype.OID    { return p.oid }
+func (p *Policy) Name() string       { return p.name }
+func (p *Policy) Namespace() string  { return p.namespace }
+func (p *Policy) TableName() string  { return p.tableName }
+func (p *Policy) Command() string    { return p.command }
+func (p *Policy) IsPermissive() bool { return p.permissive }
+func (p *Policy) Roles() []string    { return p.roles }
+
+// Covers reports whether the policy applies to the given command: "SELECT",
+// "INSERT", "UPDATE" or "DELETE".
+func (p *Policy) Covers(command string) bool {
+	return p.command == "*" || policyCommands[p.command] == command
+}
+
+// The commands of pg_policy.polcmd.
+var policyCommands = map[string]string{
+	"r": "SELECT",
+	"a": "INSERT",
+	"w": "UPDATE",
+	"d": "DELETE",
+}
+
+// Policies returns every row-level security policy on the tables in the DB.
+// Like AllIndexes, the result is cached, and safe for the caller to modify.
+func (db *DB) Policies(ctx context.Context) ([]*Policy, error) {
+	if db.stale(db.policiesLoadedAt) {
+		var result []*Policy
+		err := db.readOnly(ctx, "policies", func(tx *pgx.Tx) (err error) {
+			result, err = loadPolicies(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.policies = result
+		db.policiesLoadedAt = time.Now()
+	}
+	return append([]*Policy(nil), db.policies...), nil
+}
+
+// Returns the policies on the tables in the namespace; q.v. DB.Policies.
+func loadPolicies(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Policy, error) {
+	sql, err := sqlSelectPolicies.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var policies []*Policy
+	for rows.Next() {
+		var p Policy
+		if err := scanPolicy(rows, &p); err != nil {
+			return nil, err
+		}
+		policies = append(policies, &p)
+	}
+	return policies, rows.Err()
+}
+
+// Restrictive policies were introduced in Postgres 10.
+var sqlSelectPolicies = catalogQuery{
+	name: "policies",
+	variants: []sqlVariant{
+		{100000, strings.Replace(sqlSelectPoliciesTemplate, "$PERMISSIVE", "p.polpermissive", 1)},
+		{90600, strings.Replace(sqlSelectPoliciesTemplate, "$PERMISSIVE", "true", 1)},
+	},
+}
+
+// A policy's role 0 stands for PUBLIC.
+const sqlSelectPoliciesTemplate = `
+select p.oid,
+       p.polname,
+       ns.nspname,
+       t.relname,
+       p.polcmd::text,
+       $PERMISSIVE,
+       array(select case when r = 0 then 'public' else pg_get_userbyid(r)::text end
+               from unnest(p.polroles) r)
+  from pg_policy p
+  join pg_class t on t.oid = p.polrelid
+  join pg_namespace ns on ns.oid = t.relnamespace
+ where ns.nspname = $1
+ order by t.relname, p.polname`
+
+func scanPolicy(sc scannable, p *Policy) error {
+	return sc.Scan(
+		&p.oid,        // pg_policy.oid
+		&p.name,       // pg_policy.polname
+		&p.namespace,  // pg_namespace.nspname
+		&p.tableName,  // pg_class.relname
+		&p.command,    // pg_policy.polcmd
+		&p.permissive, // pg_policy.polpermissive
+		&p.roles,      // pg_policy.polroles
+	)
+}
@@ -0,0 +1,206 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// A ReplicationSource supplies the state of a server's replication slots and
+// standbys. Like a RuntimeSource, it is never cached.
+type ReplicationSource interface {
+	ReplicationSlots(ctx context.Context) ([]*ReplicationSlot, error) // every slot on the server
+	Replicas(ctx context.Context) ([]*Replica, error)                 // standbys streaming from the server
+}
+
+// ReplicationSlot contains information about a replication slot, which makes
+// the server retain the WAL its consumer has not yet received, even if the
+// consumer is gone.
+type ReplicationSlot struct {
+	name           string // name of the slot
+	slotType       string // "physical" or "logical"
+	plugin         string // output plugin of a logical slot
+	database       string // database of a logical slot
+	active         bool   // if true, a consumer is connected
+	retainedWAL    Bytes  // WAL retained for the slot, from restart_lsn to the current LSN
+	catalogXminAge int    // age of the slot's catalog_xmin in transactions; zero if none
+}
+
+func (s *ReplicationSlot) Name() string        { return s.name }
+func (s *ReplicationSlot) Type() string        { return s.slotType }
+func (s *ReplicationSlot) Plugin() string      { return s.plugin }
+func (s *ReplicationSlot) Database() string    { return s.database }
+func (s *ReplicationSlot) IsActive() bool      { return s.active }
+func (s *ReplicationSlot) RetainedWAL() Bytes  { return s.retainedWAL }
+func (s *ReplicationSlot) CatalogXminAge() int { return s.catalogXminAge }
+
+// IsLogical reports whether the slot is a logical decoding slot.
+func (s *ReplicationSlot) IsLogical() bool { return s.slotType == "logical" }
+
+// Replica contains information about a standby streaming WAL from the server.
+type Replica struct {
+	pid             int           // process ID of the WAL sender
+	applicationName string        // application_name of the standby
+	clientAddr      string        // IP address of the standby; empty if unknown
+	state           string        // e.g. "streaming", "catchup"
+	syncState       string        // "async", "potential", "sync" or "quorum"
+	lagBytes        Bytes         // WAL not yet replayed by the standby
+	replayLag       time.Duration // time taken to replay recent WAL; zero if unknown
+}
+
+func (r *Replica) PID() int                 { return r.pid }
+func (r *Replica) ApplicationName() string  { return r.applicationName }
+func (r *Replica) ClientAddr() string       { return r.clientAddr }
+func (r *Replica) State() string            { return r.state }
+func (r *Replica) SyncState() string        { return r.syncState }
+func (r *Replica) LagBytes() Bytes          { return r.lagBytes }
+func (r *Replica) ReplayLag() time.Duration { return r.replayLag }
+
+// ReplicationSlots returns every replication slot on the server.
+func (db *DB) ReplicationSlots(ctx context.Context) ([]*ReplicationSlot, error) {
+	var result []*ReplicationSlot
+	err := db.readOnly(ctx, "replication slots", func(tx *pgx.Tx) (err error) {
+		result, err = loadReplicationSlots(ctx, tx, db.version)
+		return err
+	})
+	return result, err
+}
+
+// Returns the replication slots; q.v. DB.ReplicationSlots.
+func loadReplicationSlots(ctx context.Context, tx *pgx.Tx, version Version) ([]*ReplicationSlot, error) {
+	sql, err := sqlSelectReplicationSlots.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var slots []*ReplicationSlot
+	for rows.Next() {
+		var s ReplicationSlot
+		if err := scanReplicationSlot(rows, &s); err != nil {
+			return nil, err
+		}
+		slots = append(slots, &s)
+	}
+	return slots, rows.Err()
+}
+
+// Replicas returns the standbys streaming from the server.
+func (db *DB) Replicas(ctx context.Context) ([]*Replica, error) {
+	var result []*Replica
+	err := db.readOnly(ctx, "replicas", func(tx *pgx.Tx) (err error) {
+		result, err = loadReplicas(ctx, tx, db.version)
+		return err
+	})
+	return result, err
+}
+
+// Returns the replicas; q.v. DB.Replicas.
+func loadReplicas(ctx context.Context, tx *pgx.Tx, version Version) ([]*Replica, error) {
+	sql, err := sqlSelectReplicas.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var replicas []*Replica
+	for rows.Next() {
+		var r Replica
+		if err := scanReplica(rows, &r); err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, &r)
+	}
+	return replicas, rows.Err()
+}
+
+// Postgres 10 renamed "xlog" to "wal" and "location" to "lsn", and added the
+// replay_lag column. On a standby, which may have cascading standbys and slots
+// of its own, the current LSN is the last one received.
+var (
+	walNames = strings.NewReplacer(
+		"$DIFF", "pg_wal_lsn_diff",
+		"$CURRENT", "case when pg_is_in_recovery() then pg_last_wal_receive_lsn() else pg_current_wal_lsn() end",
+		"$REPLAYED", "r.replay_lsn",
+		"$LAG", "extract(epoch from r.replay_lag)",
+	)
+	xlogNames = strings.NewReplacer(
+		"$DIFF", "pg_xlog_location_diff",
+		"$CURRENT", "case when pg_is_in_recovery() then pg_last_xlog_receive_location() else pg_current_xlog_location() end",
+		"$REPLAYED", "r.replay_location",
+		"$LAG", "null",
+	)
+)
+
+var sqlSelectReplicationSlots = catalogQuery{
+	name: "replication slots",
+	variants: []sqlVariant{
+		{100000, walNames.Replace(sqlSelectReplicationSlotsTemplate)},
+		{90600, xlogNames.Replace(sqlSelectReplicationSlotsTemplate)},
+	},
+}
+
+const sqlSelectReplicationSlotsTemplate = `
+select s.slot_name::text,
+       s.slot_type,
+       coalesce(s.plugin::text, ''),
+       coalesce(s.database::text, ''),
+       s.active,
+       coalesce($DIFF($CURRENT, s.restart_lsn), 0)::int8,
+       coalesce(age(s.catalog_xmin), 0)::int4
+  from pg_replication_slots s
+ order by s.slot_name`
+
+func scanReplicationSlot(sc scannable, s *ReplicationSlot) error {
+	return sc.Scan(
+		&s.name,           // pg_replication_slots.slot_name
+		&s.slotType,       // pg_replication_slots.slot_type
+		&s.plugin,         // pg_replication_slots.plugin
+		&s.database,       // pg_replication_slots.database
+		&s.active,         // pg_replication_slots.active
+		&s.retainedWAL,    // current LSN - pg_replication_slots.restart_lsn
+		&s.catalogXminAge, // age(pg_replication_slots.catalog_xmin)
+	)
+}
+
+var sqlSelectReplicas = catalogQuery{
+	name: "replicas",
+	variants: []sqlVariant{
+		{100000, walNames.Replace(sqlSelectReplicasTemplate)},
+		{90600, xlogNames.Replace(sqlSelectReplicasTemplate)},
+	},
+}
+
+const sqlSelectReplicasTemplate = `
+select r.pid,
+       coalesce(r.application_name, ''),
+       coalesce(host(r.client_addr), ''),
+       coalesce(r.state, ''),
+       coalesce(r.sync_state, ''),
+       coalesce($DIFF($CURRENT, $REPLAYED), 0)::int8,
+       coalesce($LAG, 0)::float8
+  from pg_stat_replication r
+ order by r.application_name, r.pid`
+
+func scanReplica(sc scannable, r *Replica) error {
+	var replayLag float64 // seconds
+	err := sc.Scan(
+		&r.pid,             // pg_stat_replication.pid
+		&r.applicationName, // pg_stat_replication.application_name
+		&r.clientAddr,      // pg_stat_replication.client_addr
+		&r.state,           // pg_stat_replication.state
+		&r.syncState,       // pg_stat_replication.sync_state
+		&r.lagBytes,        // current LSN - pg_stat_replication.replay_lsn
+		&replayLag,         // pg_stat_replication.replay_lag
+	)
+	r.replayLag = seconds(replayLag)
+	return err
+}
@@ -0,0 +1,341 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// Role contains information about a database role (user or group).
+type Role struct {
+	name         string // name of the role
+	isBootstrap  bool   // if true, the role is the bootstrap superuser created by initdb
+	superuser    bool   // if true, the role bypasses all permission checks
+	createRole   bool   // if true, the role can create and alter other roles
+	bypassRLS    bool   // if true, the role bypasses row-level security
+	canLogin     bool   // if true, the role is a user rather than a group
+	neverExpires bool   // if true, the role's password has no expiry date
+	passwordType string // "scram", "md5", "plain" or "none"; empty if unknown
+}
+
+func (r *Role) Name() string         { return r.name }
+func (r *Role) IsBootstrap() bool    { return r.isBootstrap }
+func (r *Role) IsSuperuser() bool    { return r.superuser }
+func (r *Role) CanCreateRole() bool  { return r.createRole }
+func (r *Role) BypassesRLS() bool    { return r.bypassRLS }
+func (r *Role) CanLogin() bool       { return r.canLogin }
+func (r *Role) NeverExpires() bool   { return r.neverExpires }
+func (r *Role) PasswordType() string { return r.passwordType }
+
+// A Grant is a set of privileges that an object grants to PUBLIC, i.e. to
+// every role.
+type Grant struct {
+	objectType string   // "schema" or "table"
+	namespace  string   // namespace of a table, or the schema's own name
+	name       string   // name of the table or schema
+	privileges []string // e.g. "CREATE", "SELECT", "UPDATE"
+}
+
+func (g *Grant) ObjectType() string   { return g.objectType }
+func (g *Grant) Namespace() string    { return g.namespace }
+func (g *Grant) Name() string         { return g.name }
+func (g *Grant) Privileges() []string { return g.privileges }
+
+// QualifiedName returns the name of a table prefixed by its namespace, unless
+// that is "public", or the name of a schema.
+func (g *Grant) QualifiedName() string {
+	if g.objectType == "schema" {
+		return g.name
+	}
+	return qualify(g.namespace, g.name)
+}
+
+// Function contains information about a function or procedure.
+type Function struct {
+	namespace       string   // namespace of the function
+	name            string   // name of the function
+	args            string   // argument types, as for ALTER FUNCTION, e.g. "integer, text"
+	owner           string   // name of the function's owner
+	securityDefiner bool     // if true, the function runs with its owner's privileges
+	config          []string // settings applied while the function runs, e.g. "search_path=public"
+}
+
+func (f *Function) Namespace() string       { return f.namespace }
+func (f *Function) Name() string            { return f.name }
+func (f *Function) Args() string            { return f.args }
+func (f *Function) Owner() string           { return f.owner }
+func (f *Function) IsSecurityDefiner() bool { return f.securityDefiner }
+func (f *Function) Config() []string        { return f.config }
+
+// QualifiedName returns the function's name and argument types, prefixed by
+// its namespace unless that is "public", e.g. "audit.log(text)".
+func (f *Function) QualifiedName() string {
+	return qualify(f.namespace, f.name) + "(" + f.args + ")"
+}
+
+// PinsSearchPath reports whether the function sets its own search_path, as a
+// SECURITY DEFINER function must to keep callers from substituting objects.
+func (f *Function) PinsSearchPath() bool {
+	for _, c := range f.config {
+		if strings.HasPrefix(c, "search_path=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Roles returns every role in the cluster, except for the predefined roles
+// whose names begin with "pg_". Password types are only known if the
+// connected role can read pg_authid. Like AllIndexes, the result is cached,
+// and safe for the caller to modify.
+func (db *DB) Roles(ctx context.Context) ([]*Role, error) {
+	if db.stale(db.rolesLoadedAt) {
+		var result []*Role
+		err := db.readOnly(ctx, "roles", func(tx *pgx.Tx) (err error) {
+			result, err = loadRoles(ctx, tx, db.version)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.roles = result
+		db.rolesLoadedAt = time.Now()
+	}
+	return append([]*Role(nil), db.roles...), nil
+}
+
+// PublicGrants returns the privileges granted to PUBLIC on every schema in
+// the database, other than the system schemas, and on the tables, views and
+// sequences in the DB's namespace. Like AllIndexes, the result is cached, and
+// safe for the caller to modify.
+func (db *DB) PublicGrants(ctx context.Context) ([]*Grant, error) {
+	if db.stale(db.grantsLoadedAt) {
+		var result []*Grant
+		err := db.readOnly(ctx, "public grants", func(tx *pgx.Tx) (err error) {
+			result, err = loadPublicGrants(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.grants = result
+		db.grantsLoadedAt = time.Now()
+	}
+	return append([]*Grant(nil), db.grants...), nil
+}
+
+// Functions returns every function and procedure in the DB's namespace. Like
+// AllIndexes, the result is cached, and safe for the caller to modify.
+func (db *DB) Functions(ctx context.Context) ([]*Function, error) {
+	if db.stale(db.functionsLoadedAt) {
+		var result []*Function
+		err := db.readOnly(ctx, "functions", func(tx *pgx.Tx) (err error) {
+			result, err = loadFunctions(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.functions = result
+		db.functionsLoadedAt = time.Now()
+	}
+	return append([]*Function(nil), db.functions...), nil
+}
+
+// Returns the roles in the cluster; q.v. DB.Roles. Only superusers can read
+// pg_authid, which holds the passwords, so check for access first rather than
+// fail.
+func loadRoles(ctx context.Context, tx *pgx.Tx, version Version) ([]*Role, error) {
+	var canReadPasswords bool
+	err := tx.QueryRowEx(ctx, `select has_table_privilege('pg_catalog.pg_authid', 'select')`, nil).Scan(&canReadPasswords)
+	if err != nil {
+		return nil, err
+	}
+	query := sqlSelectRoles
+	if !canReadPasswords {
+		query = sqlSelectRolesWithoutPasswords
+	}
+	sql, err := query.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var roles []*Role
+	for rows.Next() {
+		var r Role
+		if err := scanRole(rows, &r); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &r)
+	}
+	return roles, rows.Err()
+}
+
+// Returns the privileges granted to PUBLIC; q.v. DB.PublicGrants.
+func loadPublicGrants(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Grant, error) {
+	sql, err := sqlSelectPublicGrants.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var grants []*Grant
+	for rows.Next() {
+		var g Grant
+		if err := scanGrant(rows, &g); err != nil {
+			return nil, err
+		}
+		grants = append(grants, &g)
+	}
+	return grants, rows.Err()
+}
+
+// Returns the functions in the namespace; q.v. DB.Functions.
+func loadFunctions(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Function, error) {
+	sql, err := sqlSelectFunctions.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var functions []*Function
+	for rows.Next() {
+		var f Function
+		if err := scanFunction(rows, &f); err != nil {
+			return nil, err
+		}
+		functions = append(functions, &f)
+	}
+	return functions, rows.Err()
+}
+
+// Postgres 10 introduced SCRAM passwords; before then, a password is either
+// md5 or, if password_encryption was off, plain text.
+var (
+	sqlSelectRoles = catalogQuery{
+		name: "roles",
+		variants: []sqlVariant{
+			{90600, strings.NewReplacer(
+				"$PASSWORD", `case when a.rolpassword is null then 'none'
+            when a.rolpassword like 'md5%' then 'md5'
+            when a.rolpassword like 'SCRAM-SHA-256$%' then 'scram'
+            else 'plain'
+       end`,
+				"$JOIN", "\n  join pg_authid a on a.oid = r.oid",
+			).Replace(sqlSelectRolesTemplate)},
+		},
+	}
+	sqlSelectRolesWithoutPasswords = catalogQuery{
+		name: "roles",
+		variants: []sqlVariant{
+			{90600, strings.NewReplacer("$PASSWORD", "''", "$JOIN", "").Replace(sqlSelectRolesTemplate)},
+		},
+	}
+)
+
+const sqlSelectRolesTemplate = `
+select r.rolname::text,
+       r.oid = 10,
+       r.rolsuper,
+       r.rolcreaterole,
+       r.rolbypassrls,
+       r.rolcanlogin,
+       coalesce(r.rolvaliduntil = 'infinity', true),
+       $PASSWORD
+  from pg_roles r$JOIN
+ where r.rolname !~ '^pg_'
+ order by r.rolname`
+
+func scanRole(sc scannable, r *Role) error {
+	return sc.Scan(
+		&r.name,         // pg_roles.rolname
+		&r.isBootstrap,  // pg_roles.oid = 10
+		&r.superuser,    // pg_roles.rolsuper
+		&r.createRole,   // pg_roles.rolcreaterole
+		&r.bypassRLS,    // pg_roles.rolbypassrls
+		&r.canLogin,     // pg_roles.rolcanlogin
+		&r.neverExpires, // pg_roles.rolvaliduntil is null or infinity
+		&r.passwordType, // pg_authid.rolpassword
+	)
+}
+
+// A null ACL means the object has its default privileges, which acldefault
+// reconstructs. The grantee of privileges granted to PUBLIC is 0.
+var sqlSelectPublicGrants = catalogQuery{
+	name: "public grants",
+	variants: []sqlVariant{
+		{90600, `
+select 'schema',
+       ns.nspname,
+       ns.nspname,
+       array_agg(a.privilege_type order by a.privilege_type)
+  from pg_namespace ns
+ cross join lateral aclexplode(coalesce(ns.nspacl, acldefault('n', ns.nspowner))) a
+ where a.grantee = 0
+   and ns.nspname !~ '^pg_'
+   and ns.nspname <> 'information_schema'
+ group by ns.nspname
+union all
+select 'table',
+       ns.nspname,
+       c.relname,
+       array_agg(a.privilege_type order by a.privilege_type)
+  from pg_class c
+  join pg_namespace ns on ns.oid = c.relnamespace
+ cross join lateral aclexplode(coalesce(c.relacl, acldefault('r', c.relowner))) a
+ where a.grantee = 0
+   and ns.nspname = $1
+   and c.relkind in ('r', 'p', 'v', 'm', 'f', 'S')
+ group by ns.nspname, c.relname
+ order by 1, 2, 3`},
+	},
+}
+
+func scanGrant(sc scannable, g *Grant) error {
+	return sc.Scan(
+		&g.objectType, // "schema" or "table"
+		&g.namespace,  // pg_namespace.nspname
+		&g.name,       // pg_namespace.nspname or pg_class.relname
+		&g.privileges, // aclexplode(acl).privilege_type
+	)
+}
+
+var sqlSelectFunctions = catalogQuery{
+	name: "functions",
+	variants: []sqlVariant{
+		{90600, `
+select ns.nspname,
+       p.proname,
+       pg_get_function_identity_arguments(p.oid),
+       pg_get_userbyid(p.proowner)::text,
+       p.prosecdef,
+       coalesce(p.proconfig, '{}')
+  from pg_proc p
+  join pg_namespace ns on ns.oid = p.pronamespace
+ where ns.nspname = $1
+ order by p.proname, p.oid`},
+	},
+}
+
+func scanFunction(sc scannable, f *Function) error {
+	return sc.Scan(
+		&f.namespace,       // pg_namespace.nspname
+		&f.name,            // pg_proc.proname
+		&f.args,            // pg_get_function_identity_arguments(oid)
+		&f.owner,           // pg_get_userbyid(pg_proc.proowner)
+		&f.securityDefiner, // pg_proc.prosecdef
+		&f.config,          // pg_proc.proconfig
+	)
+}
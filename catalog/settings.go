@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// Setting contains information about a server configuration parameter.
+type Setting struct {
+	name    string // name of the parameter
+	setting string // current value, in the parameter's unit if it has one
+	unit    string // e.g. "8kB", "ms"; empty if the parameter has no unit
+	display string // current value as shown by SHOW, e.g. "128MB"
+	source  string // where the value came from, e.g. "default", "configuration file"
+}
+
+func (s *Setting) Name() string    { return s.name }
+func (s *Setting) Setting() string { return s.setting }
+func (s *Setting) Unit() string    { return s.unit }
+func (s *Setting) Display() string { return s.display }
+func (s *Setting) Source() string  { return s.source }
+
+// IsOn reports whether a boolean parameter is on.
+func (s *Setting) IsOn() bool { return s.setting == "on" }
+
+// Float returns the value of a numeric parameter, in its unit. It returns 0
+// if the value is not a number.
+func (s *Setting) Float() float64 {
+	f, _ := strconv.ParseFloat(s.setting, 64)
+	return f
+}
+
+// Bytes returns the value of a memory parameter, such as shared_buffers, in
+// bytes. It returns 0 if the parameter is not measured in bytes.
+func (s *Setting) Bytes() Bytes {
+	n, ok := settingUnits[strings.TrimLeft(s.unit, "0123456789")]
+	if !ok {
+		return 0
+	}
+	if prefix := strings.TrimRight(s.unit, "kMGTB"); prefix != "" {
+		m, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0
+		}
+		n *= Bytes(m)
+	}
+	return Bytes(s.Float()) * n
+}
+
+// The memory units of pg_settings.unit, less any multiplier, e.g. the "8" of
+// "8kB".
+var settingUnits = map[string]Bytes{
+	"B":  1,
+	"kB": KiB,
+	"MB": MiB,
+	"GB": GiB,
+	"TB": TiB,
+}
+
+// SettingsByName indexes settings by name.
+func SettingsByName(settings []*Setting) map[string]*Setting {
+	m := make(map[string]*Setting, len(settings))
+	for _, s := range settings {
+		m[s.name] = s
+	}
+	return m
+}
+
+// Settings returns the server's configuration parameters. Like AllIndexes,
+// the result is cached, and safe for the caller to modify.
+func (db *DB) Settings(ctx context.Context) ([]*Setting, error) {
+	if db.stale(db.settingsLoadedAt) {
+		var result []*Setting
+		err := db.readOnly(ctx, "settings", func(tx *pgx.Tx) (err error) {
+			result, err = loadSettings(ctx, tx, db.version)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.settings = result
+		db.settingsLoadedAt = time.Now()
+	}
+	return append([]*Setting(nil), db.settings...), nil
+}
+
+// Returns the server's configuration parameters; q.v. DB.Settings.
+func loadSettings(ctx context.Context, tx *pgx.Tx, version Version) ([]*Setting, error) {
+	sql, err := sqlSelectSettings.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var settings []*Setting
+	for rows.Next() {
+		var s Setting
+		if err := scanSetting(rows, &s); err != nil {
+			return nil, err
+		}
+		settings = append(settings, &s)
+	}
+	return settings, rows.Err()
+}
+
+var sqlSelectSettings = catalogQuery{
+	name: "settings",
+	variants: []sqlVariant{
+		{90600, `
+select s.name,
+       coalesce(s.setting, ''),
+       coalesce(s.unit, ''),
+       coalesce(current_setting(s.name, true), ''),
+       coalesce(s.source, '')
+  from pg_settings s
+ order by s.name`},
+	},
+}
+
+func scanSetting(sc scannable, s *Setting) error {
+	return sc.Scan(
+		&s.name,    // pg_settings.name
+		&s.setting, // pg_settings.setting
+		&s.unit,    // pg_settings.unit
+		&s.display, // current_setting(name)
+		&s.source,  // pg_settings.source
+	)
+}
@@ -0,0 +1,1023 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+// A Snapshot is a saved copy of a database's index catalog. Snapshots let
+// pglint compare a live database against one it cannot connect to directly,
+// and serve as test fixtures for the checks.
+type Snapshot struct {
+	Version          int            `json:"version"`
+	ServerVersionNum Version        `json:"server_version,omitempty"`
+	Database         string         `json:"database"`
+	Namespace        string         `json:"namespace"`
+	TakenAt          time.Time      `json:"taken_at"`
+	Indexes          []*Index       `json:"indexes"`
+	Tables           []*Table       `json:"tables,omitempty"`
+	Stats            []*ColumnStats `json:"column_stats,omitempty"`
+	FKeys            []*ForeignKey  `json:"foreign_keys,omitempty"`
+	TableColumns     []*TableColumn `json:"columns,omitempty"`
+	TableConstraints []*Constraint  `json:"constraints,omitempty"`
+	TableTriggers    []*Trigger     `json:"triggers,omitempty"`
+	ServerSettings   []*Setting     `json:"settings,omitempty"`
+	ServerRoles      []*Role        `json:"roles,omitempty"`
+	Grants           []*Grant       `json:"public_grants,omitempty"`
+	Funcs            []*Function    `json:"functions,omitempty"`
+	TablePolicies    []*Policy      `json:"policies,omitempty"`
+
+	// Live activity, recorded if the snapshot was taken from a RuntimeSource.
+	ActiveSessions []*Session      `json:"sessions,omitempty"`
+	Prepared       []*PreparedXact `json:"prepared_xacts,omitempty"`
+
+	// Recorded if the snapshot was taken from a ReplicationSource.
+	Slots    []*ReplicationSlot `json:"replication_slots,omitempty"`
+	Standbys []*Replica         `json:"replicas,omitempty"`
+}
+
+// ServerVersion is part of the Source interface. It is zero if the snapshot
+// was taken before pglint recorded the server's version.
+func (snap *Snapshot) ServerVersion() Version { return snap.ServerVersionNum }
+
+// AllIndexes is part of the Source interface.
+func (snap *Snapshot) AllIndexes(context.Context) ([]*Index, error) {
+	return FilterIndexes(snap.Indexes, (*Index).Usable), nil
+}
+
+// InvalidIndexes is part of the Source interface.
+func (snap *Snapshot) InvalidIndexes(context.Context) ([]*Index, error) {
+	return FilterIndexes(snap.Indexes, func(ind *Index) bool { return !ind.Usable() }), nil
+}
+
+// EveryIndex is part of the Source interface.
+func (snap *Snapshot) EveryIndex(context.Context) ([]*Index, error) {
+	return FilterIndexes(snap.Indexes, func(*Index) bool { return true }), nil
+}
+
+// AllTables is part of the Source interface.
+func (snap *Snapshot) AllTables(context.Context) ([]*Table, error) {
+	return append([]*Table(nil), snap.Tables...), nil
+}
+
+// ColumnStats is part of the Source interface.
+func (snap *Snapshot) ColumnStats(context.Context) ([]*ColumnStats, error) {
+	return append([]*ColumnStats(nil), snap.Stats...), nil
+}
+
+// ForeignKeys is part of the Source interface.
+func (snap *Snapshot) ForeignKeys(context.Context) ([]*ForeignKey, error) {
+	return append([]*ForeignKey(nil), snap.FKeys...), nil
+}
+
+// Columns is part of the Source interface.
+func (snap *Snapshot) Columns(context.Context) ([]*TableColumn, error) {
+	return append([]*TableColumn(nil), snap.TableColumns...), nil
+}
+
+// Constraints is part of the Source interface.
+func (snap *Snapshot) Constraints(context.Context) ([]*Constraint, error) {
+	return append([]*Constraint(nil), snap.TableConstraints...), nil
+}
+
+// Triggers is part of the Source interface.
+func (snap *Snapshot) Triggers(context.Context) ([]*Trigger, error) {
+	return append([]*Trigger(nil), snap.TableTriggers...), nil
+}
+
+// Settings is part of the Source interface.
+func (snap *Snapshot) Settings(context.Context) ([]*Setting, error) {
+	return append([]*Setting(nil), snap.ServerSettings...), nil
+}
+
+// Roles is part of the Source interface.
+func (snap *Snapshot) Roles(context.Context) ([]*Role, error) {
+	return append([]*Role(nil), snap.ServerRoles...), nil
+}
+
+// PublicGrants is part of the Source interface.
+func (snap *Snapshot) PublicGrants(context.Context) ([]*Grant, error) {
+	return append([]*Grant(nil), snap.Grants...), nil
+}
+
+// Functions is part of the Source interface.
+func (snap *Snapshot) Functions(context.Context) ([]*Function, error) {
+	return append([]*Function(nil), snap.Funcs...), nil
+}
+
+// Policies is part of the Source interface.
+func (snap *Snapshot) Policies(context.Context) ([]*Policy, error) {
+	return append([]*Policy(nil), snap.TablePolicies...), nil
+}
+
+// Sessions is part of the RuntimeSource interface.
+func (snap *Snapshot) Sessions(context.Context) ([]*Session, error) {
+	return append([]*Session(nil), snap.ActiveSessions...), nil
+}
+
+// PreparedXacts is part of the RuntimeSource interface.
+func (snap *Snapshot) PreparedXacts(context.Context) ([]*PreparedXact, error) {
+	return append([]*PreparedXact(nil), snap.Prepared...), nil
+}
+
+// ReplicationSlots is part of the ReplicationSource interface.
+func (snap *Snapshot) ReplicationSlots(context.Context) ([]*ReplicationSlot, error) {
+	return append([]*ReplicationSlot(nil), snap.Slots...), nil
+}
+
+// Replicas is part of the ReplicationSource interface.
+func (snap *Snapshot) Replicas(context.Context) ([]*Replica, error) {
+	return append([]*Replica(nil), snap.Standbys...), nil
+}
+
+// The current snapshot file format version.
+const snapshotVersion = 1
+
+// WriteSnapshot writes everything src supplies about the given database and
+// namespace to a snapshot file at path, replacing it if it exists. If src is
+// also a RuntimeSource or a ReplicationSource, the server's live activity or
+// replication state is recorded too.
+func WriteSnapshot(ctx context.Context, path string, src Source, database, namespace string) error {
+	snap := Snapshot{
+		Version:          snapshotVersion,
+		ServerVersionNum: src.ServerVersion(),
+		Database:         database,
+		Namespace:        namespace,
+		TakenAt:          time.Now().UTC(),
+	}
+	var err error
+	if snap.Indexes, err = src.EveryIndex(ctx); err != nil {
+		return err
+	}
+	if snap.Tables, err = src.AllTables(ctx); err != nil {
+		return err
+	}
+	if snap.Stats, err = src.ColumnStats(ctx); err != nil {
+		return err
+	}
+	if snap.FKeys, err = src.ForeignKeys(ctx); err != nil {
+		return err
+	}
+	if snap.TableColumns, err = src.Columns(ctx); err != nil {
+		return err
+	}
+	if snap.TableConstraints, err = src.Constraints(ctx); err != nil {
+		return err
+	}
+	if snap.TableTriggers, err = src.Triggers(ctx); err != nil {
+		return err
+	}
+	if snap.ServerSettings, err = src.Settings(ctx); err != nil {
+		return err
+	}
+	if snap.ServerRoles, err = src.Roles(ctx); err != nil {
+		return err
+	}
+	if snap.Grants, err = src.PublicGrants(ctx); err != nil {
+		return err
+	}
+	if snap.Funcs, err = src.Functions(ctx); err != nil {
+		return err
+	}
+	if snap.TablePolicies, err = src.Policies(ctx); err != nil {
+		return err
+	}
+	if rs, ok := src.(RuntimeSource); ok {
+		if snap.ActiveSessions, err = rs.Sessions(ctx); err != nil {
+			return err
+		}
+		if snap.Prepared, err = rs.PreparedXacts(ctx); err != nil {
+			return err
+		}
+	}
+	if rs, ok := src.(ReplicationSource); ok {
+		if snap.Slots, err = rs.ReplicationSlots(ctx); err != nil {
+			return err
+		}
+		if snap.Standbys, err = rs.Replicas(ctx); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(&snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing snapshot: %v", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot file previously created by WriteSnapshot.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %v", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %v", path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("snapshot %s: unsupported version %d", path, snap.Version)
+	}
+	return &snap, nil
+}
+
+// indexJSON is the serialized form of an Index.
+type indexJSON struct {
+	OID              pgtype.OID `json:"oid"`
+	Name             string     `json:"name"`
+	NamespaceOID     pgtype.OID `json:"namespace_oid"`
+	Namespace        string     `json:"namespace"`
+	TableOID         pgtype.OID `json:"table_oid"`
+	TableName        string     `json:"table_name"`
+	NumColumns       int        `json:"num_columns"`
+	NumKeyColumns    int        `json:"num_key_columns"`
+	IsUnique         bool       `json:"is_unique"`
+	IsPrimary        bool       `json:"is_primary"`
+	IsValid          bool       `json:"is_valid"`
+	IsReady          bool       `json:"is_ready"`
+	IsLive           bool       `json:"is_live"`
+	Keys             Int2Vector `json:"keys"`
+	Collations       OIDVector  `json:"collations"`
+	Classes          OIDVector  `json:"classes"`
+	Options          OIDVector  `json:"options"`
+	Exprs            string     `json:"exprs,omitempty"`
+	Pred             string     `json:"pred,omitempty"`
+	Definition       *string    `json:"definition,omitempty"`
+	NumPages         int        `json:"num_pages"`
+	NumRows          int        `json:"num_rows"`
+	NumTablePages    int        `json:"num_table_pages"`
+	NumTableRows     int        `json:"num_table_rows"`
+	NumScans         int        `json:"num_scans"`
+	NumTuplesRead    int        `json:"num_tuples_read"`
+	NumTuplesFetched int        `json:"num_tuples_fetched"`
+	Size             Bytes      `json:"size"`
+	ParentOID        pgtype.OID `json:"parent_oid,omitempty"`
+	Attrs            []string   `json:"attrs"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Index) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&indexJSON{
+		OID:              v.oid,
+		Name:             v.name,
+		NamespaceOID:     v.namespaceOID,
+		Namespace:        v.namespace,
+		TableOID:         v.tableOID,
+		TableName:        v.tableName,
+		NumColumns:       v.numColumns,
+		NumKeyColumns:    v.numKeyColumns,
+		IsUnique:         v.isUnique,
+		IsPrimary:        v.isPrimary,
+		IsValid:          v.isValid,
+		IsReady:          v.isReady,
+		IsLive:           v.isLive,
+		Keys:             v.keys,
+		Collations:       v.collations,
+		Classes:          v.classes,
+		Options:          v.options,
+		Exprs:            v.exprs,
+		Pred:             v.pred,
+		Definition:       v.definition,
+		NumPages:         v.numPages,
+		NumRows:          v.numRows,
+		NumTablePages:    v.numTablePages,
+		NumTableRows:     v.numTableRows,
+		NumScans:         v.numScans,
+		NumTuplesRead:    v.numTuplesRead,
+		NumTuplesFetched: v.numTuplesFetched,
+		Size:             v.size,
+		ParentOID:        v.parentOID,
+		Attrs:            v.attrs,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Index) UnmarshalJSON(b []byte) error {
+	var j indexJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*v = Index{
+		oid:              j.OID,
+		name:             j.Name,
+		namespaceOID:     j.NamespaceOID,
+		namespace:        j.Namespace,
+		tableOID:         j.TableOID,
+		tableName:        j.TableName,
+		numColumns:       j.NumColumns,
+		numKeyColumns:    j.NumKeyColumns,
+		isUnique:         j.IsUnique,
+		isPrimary:        j.IsPrimary,
+		isValid:          j.IsValid,
+		isReady:          j.IsReady,
+		isLive:           j.IsLive,
+		keys:             j.Keys,
+		collations:       j.Collations,
+		classes:          j.Classes,
+		options:          j.Options,
+		exprs:            j.Exprs,
+		pred:             j.Pred,
+		definition:       j.Definition,
+		numPages:         j.NumPages,
+		numRows:          j.NumRows,
+		numTablePages:    j.NumTablePages,
+		numTableRows:     j.NumTableRows,
+		numScans:         j.NumScans,
+		numTuplesRead:    j.NumTuplesRead,
+		numTuplesFetched: j.NumTuplesFetched,
+		size:             j.Size,
+		parentOID:        j.ParentOID,
+		attrs:            j.Attrs,
+	}
+	return nil
+}
+
+// tableJSON is the serialized form of a Table.
+type tableJSON struct {
+	OID              pgtype.OID `json:"oid"`
+	Name             string     `json:"name"`
+	Namespace        string     `json:"namespace"`
+	NumInserts       int        `json:"num_inserts"`
+	NumUpdates       int        `json:"num_updates"`
+	NumHotUpdates    int        `json:"num_hot_updates"`
+	NumDeletes       int        `json:"num_deletes"`
+	NumLiveRows      int        `json:"num_live_rows"`
+	HeapSize         Bytes      `json:"heap_size"`
+	TotalSize        Bytes      `json:"total_size"`
+	FillFactor       int        `json:"fill_factor,omitempty"`
+	RowSecurity      bool       `json:"row_security,omitempty"`
+	ForceRowSecurity bool       `json:"force_row_security,omitempty"`
+	ParentOID        pgtype.OID `json:"parent_oid,omitempty"`
+	ParentName       string     `json:"parent_name,omitempty"`
+	Partitioned      bool       `json:"partitioned,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t *Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&tableJSON{
+		OID:              t.oid,
+		Name:             t.name,
+		Namespace:        t.namespace,
+		NumInserts:       t.numInserts,
+		NumUpdates:       t.numUpdates,
+		NumHotUpdates:    t.numHotUpdates,
+		NumDeletes:       t.numDeletes,
+		NumLiveRows:      t.numLiveRows,
+		HeapSize:         t.heapSize,
+		TotalSize:        t.totalSize,
+		FillFactor:       t.fillFactor,
+		RowSecurity:      t.rowSecurity,
+		ForceRowSecurity: t.forceRowSecurity,
+		ParentOID:        t.parentOID,
+		ParentName:       t.parentName,
+		Partitioned:      t.partitioned,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Table) UnmarshalJSON(b []byte) error {
+	var j tableJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*t = Table{
+		oid:              j.OID,
+		name:             j.Name,
+		namespace:        j.Namespace,
+		numInserts:       j.NumInserts,
+		numUpdates:       j.NumUpdates,
+		numHotUpdates:    j.NumHotUpdates,
+		numDeletes:       j.NumDeletes,
+		numLiveRows:      j.NumLiveRows,
+		heapSize:         j.HeapSize,
+		totalSize:        j.TotalSize,
+		fillFactor:       j.FillFactor,
+		rowSecurity:      j.RowSecurity,
+		forceRowSecurity: j.ForceRowSecurity,
+		parentOID:        j.ParentOID,
+		parentName:       j.ParentName,
+		partitioned:      j.Partitioned,
+	}
+	if t.fillFactor == 0 {
+		t.fillFactor = 100 // the default
+	}
+	return nil
+}
+
+// columnStatsJSON is the serialized form of a ColumnStats.
+type columnStatsJSON struct {
+	TableName       string    `json:"table_name"`
+	Column          string    `json:"column"`
+	NullFrac        float64   `json:"null_frac"`
+	NumDistinct     float64   `json:"n_distinct"`
+	MostCommonVals  []string  `json:"most_common_vals,omitempty"`
+	MostCommonFreqs []float64 `json:"most_common_freqs,omitempty"`
+	Correlation     float64   `json:"correlation"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *ColumnStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&columnStatsJSON{
+		TableName:       s.tableName,
+		Column:          s.column,
+		NullFrac:        s.nullFrac,
+		NumDistinct:     s.numDistinct,
+		MostCommonVals:  s.mostCommonVals,
+		MostCommonFreqs: s.mostCommonFreqs,
+		Correlation:     s.correlation,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *ColumnStats) UnmarshalJSON(b []byte) error {
+	var j columnStatsJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*s = ColumnStats{
+		tableName:       j.TableName,
+		column:          j.Column,
+		nullFrac:        j.NullFrac,
+		numDistinct:     j.NumDistinct,
+		mostCommonVals:  j.MostCommonVals,
+		mostCommonFreqs: j.MostCommonFreqs,
+		correlation:     j.Correlation,
+	}
+	return nil
+}
+
+// foreignKeyJSON is the serialized form of a ForeignKey.
+type foreignKeyJSON struct {
+	OID          pgtype.OID `json:"oid"`
+	Name         string     `json:"name"`
+	Namespace    string     `json:"namespace"`
+	TableName    string     `json:"table_name"`
+	RefNamespace string     `json:"ref_namespace"`
+	RefTableName string     `json:"ref_table_name"`
+	MatchType    string     `json:"match_type"`
+	Columns      []Column   `json:"columns"`
+	RefColumns   []Column   `json:"ref_columns"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (fk *ForeignKey) MarshalJSON() ([]byte, error) {
+	j := foreignKeyJSON{
+		OID:          fk.oid,
+		Name:         fk.name,
+		Namespace:    fk.namespace,
+		TableName:    fk.tableName,
+		RefNamespace: fk.refNamespace,
+		RefTableName: fk.refTableName,
+		MatchType:    fk.matchType,
+	}
+	for _, cols := range fk.columns {
+		j.Columns = append(j.Columns, cols.Column)
+		j.RefColumns = append(j.RefColumns, cols.RefColumn)
+	}
+	return json.Marshal(&j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (fk *ForeignKey) UnmarshalJSON(b []byte) error {
+	var j foreignKeyJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if len(j.Columns) != len(j.RefColumns) {
+		return fmt.Errorf("foreign key %s: %d columns reference %d columns",
+			j.Name, len(j.Columns), len(j.RefColumns))
+	}
+	*fk = ForeignKey{
+		oid:          j.OID,
+		name:         j.Name,
+		namespace:    j.Namespace,
+		tableName:    j.TableName,
+		refNamespace: j.RefNamespace,
+		refTableName: j.RefTableName,
+		matchType:    j.MatchType,
+	}
+	for i := range j.Columns {
+		fk.columns = append(fk.columns, FKColumns{j.Columns[i], j.RefColumns[i]})
+	}
+	return nil
+}
+
+// tableColumnJSON is the serialized form of a TableColumn.
+type tableColumnJSON struct {
+	TableOID     pgtype.OID `json:"table_oid"`
+	Namespace    string     `json:"namespace"`
+	TableName    string     `json:"table_name"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	TypeName     string     `json:"type_name"`
+	Length       *int       `json:"length,omitempty"`
+	NotNull      bool       `json:"not_null"`
+	Default      string     `json:"default,omitempty"`
+	Identity     string     `json:"identity,omitempty"`
+	InPrimaryKey bool       `json:"in_primary_key,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c *TableColumn) MarshalJSON() ([]byte, error) {
+	j := tableColumnJSON{
+		TableOID:     c.tableOID,
+		Namespace:    c.namespace,
+		TableName:    c.tableName,
+		Name:         c.name,
+		Type:         c.typ,
+		TypeName:     c.typeName,
+		NotNull:      c.notNull,
+		Default:      c.defaultExpr,
+		Identity:     c.identity,
+		InPrimaryKey: c.inPrimaryKey,
+	}
+	if c.length >= 0 {
+		j.Length = &c.length
+	}
+	return json.Marshal(&j)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *TableColumn) UnmarshalJSON(b []byte) error {
+	var j tableColumnJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*c = TableColumn{
+		tableOID:     j.TableOID,
+		namespace:    j.Namespace,
+		tableName:    j.TableName,
+		name:         j.Name,
+		typ:          j.Type,
+		typeName:     j.TypeName,
+		length:       -1,
+		notNull:      j.NotNull,
+		defaultExpr:  j.Default,
+		identity:     j.Identity,
+		inPrimaryKey: j.InPrimaryKey,
+	}
+	if j.Length != nil {
+		c.length = *j.Length
+	}
+	return nil
+}
+
+// constraintJSON is the serialized form of a Constraint.
+type constraintJSON struct {
+	OID         pgtype.OID `json:"oid"`
+	Name        string     `json:"name"`
+	Namespace   string     `json:"namespace"`
+	TableName   string     `json:"table_name"`
+	Kind        string     `json:"kind"`
+	IsValidated bool       `json:"is_validated"`
+	Definition  string     `json:"definition"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c *Constraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&constraintJSON{
+		OID:         c.oid,
+		Name:        c.name,
+		Namespace:   c.namespace,
+		TableName:   c.tableName,
+		Kind:        c.kind,
+		IsValidated: c.isValidated,
+		Definition:  c.definition,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Constraint) UnmarshalJSON(b []byte) error {
+	var j constraintJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*c = Constraint{
+		oid:         j.OID,
+		name:        j.Name,
+		namespace:   j.Namespace,
+		tableName:   j.TableName,
+		kind:        j.Kind,
+		isValidated: j.IsValidated,
+		definition:  j.Definition,
+	}
+	return nil
+}
+
+// triggerJSON is the serialized form of a Trigger.
+type triggerJSON struct {
+	OID          pgtype.OID `json:"oid"`
+	Name         string     `json:"name"`
+	Namespace    string     `json:"namespace"`
+	TableName    string     `json:"table_name"`
+	Enabled      string     `json:"enabled"`
+	IsInternal   bool       `json:"is_internal,omitempty"`
+	FunctionName string     `json:"function_name"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (tg *Trigger) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&triggerJSON{
+		OID:          tg.oid,
+		Name:         tg.name,
+		Namespace:    tg.namespace,
+		TableName:    tg.tableName,
+		Enabled:      tg.enabled,
+		IsInternal:   tg.isInternal,
+		FunctionName: tg.functionName,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (tg *Trigger) UnmarshalJSON(b []byte) error {
+	var j triggerJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*tg = Trigger{
+		oid:          j.OID,
+		name:         j.Name,
+		namespace:    j.Namespace,
+		tableName:    j.TableName,
+		enabled:      j.Enabled,
+		isInternal:   j.IsInternal,
+		functionName: j.FunctionName,
+	}
+	return nil
+}
+
+// sessionJSON is the serialized form of a Session. Ages are in seconds.
+type sessionJSON struct {
+	PID         int     `json:"pid"`
+	User        string  `json:"user"`
+	Database    string  `json:"database"`
+	Application string  `json:"application,omitempty"`
+	State       string  `json:"state"`
+	XactAge     float64 `json:"xact_age,omitempty"`
+	StateAge    float64 `json:"state_age"`
+	WaitEvent   string  `json:"wait_event,omitempty"`
+	WaitingOn   string  `json:"waiting_on,omitempty"`
+	Query       string  `json:"query"`
+	BlockedBy   []int32 `json:"blocked_by,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *Session) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&sessionJSON{
+		PID:         s.pid,
+		User:        s.user,
+		Database:    s.database,
+		Application: s.application,
+		State:       s.state,
+		XactAge:     s.xactAge.Seconds(),
+		StateAge:    s.stateAge.Seconds(),
+		WaitEvent:   s.waitEvent,
+		WaitingOn:   s.waitingOn,
+		Query:       s.query,
+		BlockedBy:   s.blockedBy,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Session) UnmarshalJSON(b []byte) error {
+	var j sessionJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*s = Session{
+		pid:         j.PID,
+		user:        j.User,
+		database:    j.Database,
+		application: j.Application,
+		state:       j.State,
+		xactAge:     seconds(j.XactAge),
+		stateAge:    seconds(j.StateAge),
+		waitEvent:   j.WaitEvent,
+		waitingOn:   j.WaitingOn,
+		query:       j.Query,
+		blockedBy:   j.BlockedBy,
+	}
+	return nil
+}
+
+// preparedXactJSON is the serialized form of a PreparedXact. The age is in
+// seconds.
+type preparedXactJSON struct {
+	GID      string  `json:"gid"`
+	Age      float64 `json:"age"`
+	Owner    string  `json:"owner"`
+	Database string  `json:"database"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p *PreparedXact) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&preparedXactJSON{
+		GID:      p.gid,
+		Age:      p.age.Seconds(),
+		Owner:    p.owner,
+		Database: p.database,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *PreparedXact) UnmarshalJSON(b []byte) error {
+	var j preparedXactJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*p = PreparedXact{
+		gid:      j.GID,
+		age:      seconds(j.Age),
+		owner:    j.Owner,
+		database: j.Database,
+	}
+	return nil
+}
+
+// replicationSlotJSON is the serialized form of a ReplicationSlot.
+type replicationSlotJSON struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Plugin         string `json:"plugin,omitempty"`
+	Database       string `json:"database,omitempty"`
+	Active         bool   `json:"active"`
+	RetainedWAL    Bytes  `json:"retained_wal"`
+	CatalogXminAge int    `json:"catalog_xmin_age,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *ReplicationSlot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&replicationSlotJSON{
+		Name:           s.name,
+		Type:           s.slotType,
+		Plugin:         s.plugin,
+		Database:       s.database,
+		Active:         s.active,
+		RetainedWAL:    s.retainedWAL,
+		CatalogXminAge: s.catalogXminAge,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *ReplicationSlot) UnmarshalJSON(b []byte) error {
+	var j replicationSlotJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*s = ReplicationSlot{
+		name:           j.Name,
+		slotType:       j.Type,
+		plugin:         j.Plugin,
+		database:       j.Database,
+		active:         j.Active,
+		retainedWAL:    j.RetainedWAL,
+		catalogXminAge: j.CatalogXminAge,
+	}
+	return nil
+}
+
+// replicaJSON is the serialized form of a Replica. The replay lag is in
+// seconds.
+type replicaJSON struct {
+	PID             int     `json:"pid"`
+	ApplicationName string  `json:"application_name"`
+	ClientAddr      string  `json:"client_addr,omitempty"`
+	State           string  `json:"state"`
+	SyncState       string  `json:"sync_state"`
+	LagBytes        Bytes   `json:"lag_bytes"`
+	ReplayLag       float64 `json:"replay_lag,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Replica) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&replicaJSON{
+		PID:             r.pid,
+		ApplicationName: r.applicationName,
+		ClientAddr:      r.clientAddr,
+		State:           r.state,
+		SyncState:       r.syncState,
+		LagBytes:        r.lagBytes,
+		ReplayLag:       r.replayLag.Seconds(),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Replica) UnmarshalJSON(b []byte) error {
+	var j replicaJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = Replica{
+		pid:             j.PID,
+		applicationName: j.ApplicationName,
+		clientAddr:      j.ClientAddr,
+		state:           j.State,
+		syncState:       j.SyncState,
+		lagBytes:        j.LagBytes,
+		replayLag:       seconds(j.ReplayLag),
+	}
+	return nil
+}
+
+// settingJSON is the serialized form of a Setting.
+type settingJSON struct {
+	Name    string `json:"name"`
+	Setting string `json:"setting"`
+	Unit    string `json:"unit,omitempty"`
+	Display string `json:"display,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *Setting) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&settingJSON{
+		Name:    s.name,
+		Setting: s.setting,
+		Unit:    s.unit,
+		Display: s.display,
+		Source:  s.source,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Setting) UnmarshalJSON(b []byte) error {
+	var j settingJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*s = Setting{
+		name:    j.Name,
+		setting: j.Setting,
+		unit:    j.Unit,
+		display: j.Display,
+		source:  j.Source,
+	}
+	return nil
+}
+
+// roleJSON is the serialized form of a Role.
+type roleJSON struct {
+	Name         string `json:"name"`
+	IsBootstrap  bool   `json:"is_bootstrap,omitempty"`
+	Superuser    bool   `json:"superuser,omitempty"`
+	CreateRole   bool   `json:"create_role,omitempty"`
+	BypassRLS    bool   `json:"bypass_rls,omitempty"`
+	CanLogin     bool   `json:"can_login,omitempty"`
+	NeverExpires bool   `json:"never_expires,omitempty"`
+	PasswordType string `json:"password_type,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&roleJSON{
+		Name:         r.name,
+		IsBootstrap:  r.isBootstrap,
+		Superuser:    r.superuser,
+		CreateRole:   r.createRole,
+		BypassRLS:    r.bypassRLS,
+		CanLogin:     r.canLogin,
+		NeverExpires: r.neverExpires,
+		PasswordType: r.passwordType,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Role) UnmarshalJSON(b []byte) error {
+	var j roleJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*r = Role{
+		name:         j.Name,
+		isBootstrap:  j.IsBootstrap,
+		superuser:    j.Superuser,
+		createRole:   j.CreateRole,
+		bypassRLS:    j.BypassRLS,
+		canLogin:     j.CanLogin,
+		neverExpires: j.NeverExpires,
+		passwordType: j.PasswordType,
+	}
+	return nil
+}
+
+// grantJSON is the serialized form of a Grant.
+type grantJSON struct {
+	ObjectType string   `json:"object_type"`
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	Privileges []string `json:"privileges"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (g *Grant) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&grantJSON{
+		ObjectType: g.objectType,
+		Namespace:  g.namespace,
+		Name:       g.name,
+		Privileges: g.privileges,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (g *Grant) UnmarshalJSON(b []byte) error {
+	var j grantJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*g = Grant{
+		objectType: j.ObjectType,
+		namespace:  j.Namespace,
+		name:       j.Name,
+		privileges: j.Privileges,
+	}
+	return nil
+}
+
+// functionJSON is the serialized form of a Function.
+type functionJSON struct {
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	Args            string   `json:"args"`
+	Owner           string   `json:"owner"`
+	SecurityDefiner bool     `json:"security_definer,omitempty"`
+	Config          []string `json:"config,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f *Function) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&functionJSON{
+		Namespace:       f.namespace,
+		Name:            f.name,
+		Args:            f.args,
+		Owner:           f.owner,
+		SecurityDefiner: f.securityDefiner,
+		Config:          f.config,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *Function) UnmarshalJSON(b []byte) error {
+	var j functionJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*f = Function{
+		namespace:       j.Namespace,
+		name:            j.Name,
+		args:            j.Args,
+		owner:           j.Owner,
+		securityDefiner: j.SecurityDefiner,
+		config:          j.Config,
+	}
+	return nil
+}
+
+// policyJSON is the serialized form of a Policy.
+type policyJSON struct {
+	OID        pgtype.OID `json:"oid"`
+	Name       string     `json:"name"`
+	Namespace  string     `json:"namespace"`
+	TableName  string     `json:"table_name"`
+	Command    string     `json:"command"`
+	Permissive bool       `json:"permissive"`
+	Roles      []string   `json:"roles"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p *Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&policyJSON{
+		OID:        p.oid,
+		Name:       p.name,
+		Namespace:  p.namespace,
+		TableName:  p.tableName,
+		Command:    p.command,
+		Permissive: p.permissive,
+		Roles:      p.roles,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Policy) UnmarshalJSON(b []byte) error {
+	var j policyJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*p = Policy{
+		oid:        j.OID,
+		name:       j.Name,
+		namespace:  j.Namespace,
+		tableName:  j.TableName,
+		command:    j.Command,
+		permissive: j.Permissive,
+		roles:      j.Roles,
+	}
+	return nil
+}
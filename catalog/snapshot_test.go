@@ -0,0 +1,170 @@
+package catalog
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	def := "CREATE INDEX t_x_idx ON public.t USING btree (x)"
+	indexes := []*Index{
+		{
+			oid: 1, name: "t_x_idx", namespace: "public", tableOID: 2, tableName: "t",
+			numColumns: 1, numKeyColumns: 1, isValid: true, isReady: true, isLive: true,
+			keys: Int2Vector{1}, collations: OIDVector{0}, classes: OIDVector{1978}, options: OIDVector{0},
+			definition: &def, numRows: 10, numScans: 3, size: 8192, parentOID: 41, attrs: []string{"x"},
+		},
+		{
+			oid: 3, name: "t_lower_y_idx", namespace: "public", tableOID: 2, tableName: "t",
+			numColumns: 1, numKeyColumns: 1, isUnique: true,
+			keys: Int2Vector{0}, collations: OIDVector{100}, classes: OIDVector{3126}, options: OIDVector{0},
+			exprs: "lower(y)", pred: "(y IS NOT NULL)", attrs: []string{"lower(y)"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "snap.json")
+	tables := []*Table{
+		{oid: 2, name: "t", namespace: "public", numInserts: 10, numUpdates: 5, numHotUpdates: 4, numLiveRows: 10,
+			heapSize: 8192, totalSize: 24576, fillFactor: 90, rowSecurity: true,
+			parentOID: 40, parentName: "p"},
+	}
+	stats := []*ColumnStats{
+		{tableName: "t", column: "x", nullFrac: 0.1, numDistinct: -0.5,
+			mostCommonVals: []string{"a", "b"}, mostCommonFreqs: []float64{0.2, 0.1}, correlation: 0.9},
+	}
+	fkeys := []*ForeignKey{
+		{oid: 4, name: "t_x_fkey", namespace: "public", tableName: "t", refNamespace: "s", refTableName: "u",
+			matchType: "s", columns: []FKColumns{
+				{Column{"x", "integer", "", true}, Column{"id", "bigint", "", true}},
+				{Column{"y", "text", "C", false}, Column{"y", "text", "default", true}},
+			}},
+	}
+	columns := []*TableColumn{
+		{tableOID: 2, namespace: "public", tableName: "t", name: "x", typ: "integer", typeName: "int4", length: -1,
+			notNull: true, defaultExpr: "nextval('t_x_seq'::regclass)", inPrimaryKey: true},
+		{tableOID: 2, namespace: "public", tableName: "t", name: "y", typ: "character varying(3)", typeName: "varchar",
+			length: 3},
+	}
+	constraints := []*Constraint{
+		{oid: 5, name: "t_x_check", namespace: "public", tableName: "t", kind: "c", definition: "CHECK ((x > 0)) NOT VALID"},
+	}
+	triggers := []*Trigger{
+		{oid: 6, name: "t_audit", namespace: "public", tableName: "t", enabled: "D", functionName: "audit"},
+	}
+	settings := []*Setting{
+		{name: "shared_buffers", setting: "16384", unit: "8kB", display: "128MB", source: "configuration file"},
+		{name: "fsync", setting: "on", display: "on", source: "default"},
+	}
+	roles := []*Role{
+		{name: "postgres", isBootstrap: true, superuser: true, createRole: true, bypassRLS: true, canLogin: true,
+			neverExpires: true, passwordType: "scram"},
+		{name: "app", canLogin: true, passwordType: "md5"},
+	}
+	grants := []*Grant{
+		{objectType: "schema", namespace: "public", name: "public", privileges: []string{"CREATE", "USAGE"}},
+	}
+	functions := []*Function{
+		{namespace: "public", name: "f", args: "integer, text", owner: "app", securityDefiner: true,
+			config: []string{"search_path=public"}},
+	}
+	policies := []*Policy{
+		{oid: 7, name: "t_tenant", namespace: "public", tableName: "t", command: "*", permissive: true,
+			roles: []string{"public"}},
+	}
+	sessions := []*Session{
+		{pid: 101, user: "app", database: "db", state: "idle in transaction", xactAge: 90 * time.Second,
+			stateAge: 1500 * time.Millisecond, query: "update t set x = 1"},
+		{pid: 102, user: "app", database: "db", state: "active", xactAge: 2 * time.Second,
+			stateAge: 2 * time.Second, waitEvent: "Lock", waitingOn: "t", query: "delete from t",
+			blockedBy: []int32{101}},
+	}
+	prepared := []*PreparedXact{
+		{gid: "tx1", age: time.Hour, owner: "app", database: "db"},
+	}
+	slots := []*ReplicationSlot{
+		{name: "standby1", slotType: "physical", active: true, retainedWAL: 16 * MiB},
+		{name: "cdc", slotType: "logical", plugin: "pgoutput", database: "db", retainedWAL: 40 * GiB,
+			catalogXminAge: 123456},
+	}
+	replicas := []*Replica{
+		{pid: 201, applicationName: "standby1", clientAddr: "10.0.0.2", state: "streaming", syncState: "async",
+			lagBytes: 4096, replayLag: 250 * time.Millisecond},
+	}
+	src := &Snapshot{
+		ServerVersionNum: 90624, Indexes: indexes, Tables: tables, Stats: stats, FKeys: fkeys,
+		TableColumns: columns, TableConstraints: constraints, TableTriggers: triggers, ServerSettings: settings,
+		ServerRoles: roles, Grants: grants, Funcs: functions, TablePolicies: policies,
+		ActiveSessions: sessions, Prepared: prepared, Slots: slots, Standbys: replicas,
+	}
+	if err := WriteSnapshot(context.Background(), path, src, "db", "public"); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Database != "db" || snap.Namespace != "public" || snap.ServerVersion() != 90624 {
+		t.Errorf("got database %q, namespace %q, server version %s", snap.Database, snap.Namespace, snap.ServerVersion())
+	}
+	if !reflect.DeepEqual(snap.Indexes, indexes) {
+		t.Errorf("indexes changed in round trip:\ngot  %+v\nwant %+v", snap.Indexes, indexes)
+	}
+	if !reflect.DeepEqual(snap.Tables, tables) {
+		t.Errorf("tables changed in round trip:\ngot  %+v\nwant %+v", snap.Tables, tables)
+	}
+	if !reflect.DeepEqual(snap.Stats, stats) {
+		t.Errorf("column stats changed in round trip:\ngot  %+v\nwant %+v", snap.Stats, stats)
+	}
+	if !reflect.DeepEqual(snap.TableColumns, columns) {
+		t.Errorf("columns changed in round trip:\ngot  %+v\nwant %+v", snap.TableColumns, columns)
+	}
+	if !reflect.DeepEqual(snap.TableConstraints, constraints) {
+		t.Errorf("constraints changed in round trip:\ngot  %+v\nwant %+v", snap.TableConstraints, constraints)
+	}
+	if !reflect.DeepEqual(snap.TableTriggers, triggers) {
+		t.Errorf("triggers changed in round trip:\ngot  %+v\nwant %+v", snap.TableTriggers, triggers)
+	}
+	if !reflect.DeepEqual(snap.FKeys, fkeys) {
+		t.Errorf("foreign keys changed in round trip:\ngot  %+v\nwant %+v", snap.FKeys, fkeys)
+	}
+	if !reflect.DeepEqual(snap.ServerSettings, settings) {
+		t.Errorf("settings changed in round trip:\ngot  %+v\nwant %+v", snap.ServerSettings, settings)
+	}
+	if !reflect.DeepEqual(snap.ServerRoles, roles) {
+		t.Errorf("roles changed in round trip:\ngot  %+v\nwant %+v", snap.ServerRoles, roles)
+	}
+	if !reflect.DeepEqual(snap.Grants, grants) {
+		t.Errorf("grants changed in round trip:\ngot  %+v\nwant %+v", snap.Grants, grants)
+	}
+	if !reflect.DeepEqual(snap.Funcs, functions) {
+		t.Errorf("functions changed in round trip:\ngot  %+v\nwant %+v", snap.Funcs, functions)
+	}
+	if !reflect.DeepEqual(snap.TablePolicies, policies) {
+		t.Errorf("policies changed in round trip:\ngot  %+v\nwant %+v", snap.TablePolicies, policies)
+	}
+	if !reflect.DeepEqual(snap.ActiveSessions, sessions) {
+		t.Errorf("sessions changed in round trip:\ngot  %+v\nwant %+v", snap.ActiveSessions, sessions)
+	}
+	if !reflect.DeepEqual(snap.Prepared, prepared) {
+		t.Errorf("prepared transactions changed in round trip:\ngot  %+v\nwant %+v", snap.Prepared, prepared)
+	}
+	if !reflect.DeepEqual(snap.Slots, slots) {
+		t.Errorf("replication slots changed in round trip:\ngot  %+v\nwant %+v", snap.Slots, slots)
+	}
+	if !reflect.DeepEqual(snap.Standbys, replicas) {
+		t.Errorf("replicas changed in round trip:\ngot  %+v\nwant %+v", snap.Standbys, replicas)
+	}
+
+	// The snapshot is also a Source.
+	valid, _ := snap.AllIndexes(ctx)
+	invalid, _ := snap.InvalidIndexes(ctx)
+	if len(valid) != 1 || valid[0].Name() != "t_x_idx" {
+		t.Errorf("AllIndexes() = %v, want [t_x_idx]", valid)
+	}
+	if len(invalid) != 1 || invalid[0].Name() != "t_lower_y_idx" {
+		t.Errorf("InvalidIndexes() = %v, want [t_lower_y_idx]", invalid)
+	}
+}
@@ -0,0 +1,138 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// ColumnStats holds the planner's statistics for a table column, as gathered
+// by ANALYZE.
+type ColumnStats struct {
+	tableName       string    // name of the column's table
+	column          string    // name of the column
+	nullFrac        float64   // fraction of entries that are null
+	numDistinct     float64   // estimated distinct values; q.v. NumDistinct
+	mostCommonVals  []string  // the most common values, as text
+	mostCommonFreqs []float64 // frequencies of the most common values
+	correlation     float64   // correlation of physical and logical row order
+}
+
+func (s *ColumnStats) TableName() string          { return s.tableName }
+func (s *ColumnStats) Column() string             { return s.column }
+func (s *ColumnStats) NullFrac() float64          { return s.nullFrac }
+func (s *ColumnStats) MostCommonVals() []string   { return s.mostCommonVals }
+func (s *ColumnStats) MostCommonFreqs() []float64 { return s.mostCommonFreqs }
+func (s *ColumnStats) Correlation() float64       { return s.correlation }
+
+// NumDistinct reports pg_stats.n_distinct: if positive, the estimated number
+// of distinct values in the column; if negative, the negated number of
+// distinct values divided by the number of rows, which means the number of
+// distinct values grows with the table.
+func (s *ColumnStats) NumDistinct() float64 { return s.numDistinct }
+
+// DistinctValues estimates the number of distinct values in the column, given
+// the number of rows in its table.
+func (s *ColumnStats) DistinctValues(numRows int) float64 {
+	if s.numDistinct < 0 {
+		return -s.numDistinct * float64(numRows)
+	}
+	return s.numDistinct
+}
+
+// ColumnStats returns the statistics of every analyzed column in the DB. Like
+// AllIndexes, the result is cached, and safe for the caller to modify.
+func (db *DB) ColumnStats(ctx context.Context) ([]*ColumnStats, error) {
+	if db.stale(db.statsLoadedAt) {
+		var result []*ColumnStats
+		err := db.readOnly(ctx, "column stats", func(tx *pgx.Tx) (err error) {
+			result, err = loadColumnStats(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.stats = result
+		db.statsLoadedAt = time.Now()
+	}
+	return append([]*ColumnStats(nil), db.stats...), nil
+}
+
+// Returns the statistics for every column in the namespace; q.v.
+// DB.ColumnStats.
+func loadColumnStats(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*ColumnStats, error) {
+	sql, err := sqlSelectColumnStats.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stats []*ColumnStats
+	for rows.Next() {
+		var s ColumnStats
+		if err := scanColumnStats(rows, &s); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
+
+// Statistics of inheritance trees are excluded; each table is analyzed alone.
+// A partitioned table, however, only has statistics of its whole tree, which
+// describe the rows of its partitions. The most common values are of the
+// column's type, so they are cast to text.
+var sqlSelectColumnStats = catalogQuery{
+	name: "column statistics",
+	variants: []sqlVariant{
+		{100000, strings.Replace(sqlSelectColumnStatsTemplate, "$INHERITED", `
+  join pg_namespace n on n.nspname = s.schemaname
+  join pg_class c on c.relnamespace = n.oid and c.relname = s.tablename
+ where s.schemaname = $1
+   and s.inherited = (c.relkind = 'p')`, 1)},
+		{90600, strings.Replace(sqlSelectColumnStatsTemplate, "$INHERITED", `
+ where s.schemaname = $1
+   and not s.inherited`, 1)},
+	},
+}
+
+const sqlSelectColumnStatsTemplate = `
+select s.tablename,
+       s.attname,
+       s.null_frac::float8,
+       s.n_distinct::float8,
+       coalesce(s.most_common_vals::text::text[], '{}'),
+       coalesce(s.most_common_freqs, '{}')::float8[],
+       coalesce(s.correlation, 0)::float8
+  from pg_stats s$INHERITED`
+
+func scanColumnStats(sc scannable, s *ColumnStats) error {
+	return sc.Scan(
+		&s.tableName,       // pg_stats.tablename
+		&s.column,          // pg_stats.attname
+		&s.nullFrac,        // pg_stats.null_frac
+		&s.numDistinct,     // pg_stats.n_distinct
+		&s.mostCommonVals,  // pg_stats.most_common_vals
+		&s.mostCommonFreqs, // pg_stats.most_common_freqs
+		&s.correlation,     // pg_stats.correlation
+	)
+}
+
+// ColumnStatsKey identifies a column's statistics within a namespace.
+type ColumnStatsKey struct {
+	Table, Column string
+}
+
+// ColumnStatsByName maps each table and column name to its statistics.
+func ColumnStatsByName(stats []*ColumnStats) map[ColumnStatsKey]*ColumnStats {
+	m := make(map[ColumnStatsKey]*ColumnStats, len(stats))
+	for _, s := range stats {
+		m[ColumnStatsKey{s.tableName, s.column}] = s
+	}
+	return m
+}
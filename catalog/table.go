@@ -0,0 +1,213 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// Table contains information about a PostgreSQL table and its activity.
+type Table struct {
+	oid              pgtype.OID // unique identifier of the table
+	name             string     // name of the table
+	namespace        string     // the table namespace
+	numInserts       int        // count of rows inserted
+	numUpdates       int        // count of rows updated, including HOT updates
+	numHotUpdates    int        // count of rows HOT updated, i.e. without touching indexes
+	numDeletes       int        // count of rows deleted
+	numLiveRows      int        // estimated count of live rows
+	heapSize         Bytes      // size on disk, excluding indexes
+	totalSize        Bytes      // size on disk, including indexes and TOAST
+	fillFactor       int        // percentage of each heap page filled by inserts
+	rowSecurity      bool       // if true, row-level security is enabled
+	forceRowSecurity bool       // if true, row-level security applies to the owner too
+	parentOID        pgtype.OID // partitioned table of which this is a partition; 0 if none
+	parentName       string     // name of the partitioned table; empty if none
+	partitioned      bool       // if true, a partitioned table, whose rows are in its partitions
+}
+
+func (t *Table) OID() pgtype.OID        { return t.oid }
+func (t *Table) Name() string           { return t.name }
+func (t *Table) Namespace() string      { return t.namespace }
+func (t *Table) NumInserts() int        { return t.numInserts }
+func (t *Table) NumUpdates() int        { return t.numUpdates }
+func (t *Table) NumHotUpdates() int     { return t.numHotUpdates }
+func (t *Table) NumDeletes() int        { return t.numDeletes }
+func (t *Table) NumLiveRows() int       { return t.numLiveRows }
+func (t *Table) HeapSize() Bytes        { return t.heapSize }
+func (t *Table) TotalSize() Bytes       { return t.totalSize }
+func (t *Table) FillFactor() int        { return t.fillFactor }
+func (t *Table) RowSecurity() bool      { return t.rowSecurity }
+func (t *Table) ForceRowSecurity() bool { return t.forceRowSecurity }
+func (t *Table) ParentOID() pgtype.OID  { return t.parentOID }
+func (t *Table) ParentName() string     { return t.parentName }
+func (t *Table) IsPartitioned() bool    { return t.partitioned }
+
+// IsPartition reports whether the table is a partition of a partitioned table.
+// Children of tables that use inheritance without declarative partitioning
+// are not partitions.
+func (t *Table) IsPartition() bool { return t.parentOID != 0 }
+
+// QualifiedName returns the table name prefixed by its namespace. If the
+// namespace is "public", however, it is omitted for brevity.
+func (t *Table) QualifiedName() string {
+	if t.namespace == "public" {
+		return t.name
+	}
+	return t.namespace + "." + t.name
+}
+
+// IndexWrites estimates how many index tuples each of the table's indexes has
+// had to write or clean up since statistics were last reset: an insert or a
+// non-HOT update adds an entry to every index, and a delete leaves one behind
+// for vacuum to remove.
+func (t *Table) IndexWrites() int {
+	return t.numInserts + (t.numUpdates - t.numHotUpdates) + t.numDeletes
+}
+
+// HotUpdateRatio reports the fraction of updates that were heap-only tuple
+// (HOT) updates, which don't touch the table's indexes. A table that has never
+// been updated has a ratio of 1.
+func (t *Table) HotUpdateRatio() float64 {
+	if t.numUpdates == 0 {
+		return 1
+	}
+	return float64(t.numHotUpdates) / float64(t.numUpdates)
+}
+
+// AllTables returns every table in the DB along with its statistics. Like
+// AllIndexes, the result is cached, and safe for the caller to modify.
+// Partitioned tables are included, though their statistics are zero: the
+// activity is recorded against their partitions.
+func (db *DB) AllTables(ctx context.Context) ([]*Table, error) {
+	if db.stale(db.tablesLoadedAt) {
+		var result []*Table
+		err := db.readOnly(ctx, "tables", func(tx *pgx.Tx) (err error) {
+			result, err = loadTables(ctx, tx, db.version, db.namespace)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		db.tables = result
+		db.tablesLoadedAt = time.Now()
+	}
+	return append([]*Table(nil), db.tables...), nil
+}
+
+// Returns all tables in the namespace; q.v. DB.AllTables.
+func loadTables(ctx context.Context, tx *pgx.Tx, version Version, namespace string) ([]*Table, error) {
+	sql, err := sqlSelectTableStats.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryEx(ctx, sql, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []*Table
+	for rows.Next() {
+		var t Table
+		if err := scanTable(rows, &t); err != nil {
+			return nil, err
+		}
+		tables = append(tables, &t)
+	}
+	return tables, rows.Err()
+}
+
+// Declarative partitioning was introduced in Postgres 10. Before Postgres
+// 14, pg_stat_user_tables omits partitioned tables, so they are added from
+// pg_class.
+var sqlSelectTableStats = catalogQuery{
+	name: "table statistics",
+	variants: []sqlVariant{
+		{100000, strings.NewReplacer(
+			"$PARENT", "coalesce(par.oid, 0),\n       coalesce(par.relname, ''),\n       c.relkind = 'p'",
+			"$JOIN", `
+  left join lateral (select p.oid, p.relname
+                       from pg_inherits inh
+                       join pg_partitioned_table pt on pt.partrelid = inh.inhparent
+                       join pg_class p on p.oid = inh.inhparent
+                      where inh.inhrelid = s.relid) par on true`,
+		).Replace(sqlSelectTableStatsTemplate) + sqlSelectPartitionedTables},
+		{90600, strings.NewReplacer("$PARENT", "0::oid,\n       '',\n       false", "$JOIN", "").Replace(sqlSelectTableStatsTemplate)},
+	},
+}
+
+const sqlSelectTableStatsTemplate = `
+select s.relid,
+       s.relname,
+       s.schemaname,
+       s.n_tup_ins,
+       s.n_tup_upd,
+       s.n_tup_hot_upd,
+       s.n_tup_del,
+       s.n_live_tup,
+       pg_table_size(s.relid),
+       pg_total_relation_size(s.relid),
+       coalesce((select o.option_value::int
+                   from pg_options_to_table(c.reloptions) o
+                  where o.option_name = 'fillfactor'), 100),
+       c.relrowsecurity,
+       c.relforcerowsecurity,
+       $PARENT
+  from pg_stat_user_tables s
+  join pg_class c on c.oid = s.relid$JOIN
+ where s.schemaname = $1`
+
+// The partitioned tables missing from pg_stat_user_tables, with zero stats.
+const sqlSelectPartitionedTables = `
+union all
+select c.oid,
+       c.relname,
+       n.nspname,
+       0, 0, 0, 0, 0, 0, 0, 100,
+       c.relrowsecurity,
+       c.relforcerowsecurity,
+       coalesce(par.oid, 0),
+       coalesce(par.relname, ''),
+       true
+  from pg_class c
+  join pg_namespace n on n.oid = c.relnamespace
+  left join lateral (select p.oid, p.relname
+                       from pg_inherits inh
+                       join pg_class p on p.oid = inh.inhparent
+                      where inh.inhrelid = c.oid) par on true
+ where c.relkind = 'p'
+   and n.nspname = $1
+   and not exists (select 1 from pg_stat_user_tables s where s.relid = c.oid)`
+
+func scanTable(sc scannable, t *Table) error {
+	return sc.Scan(
+		&t.oid,              // pg_stat_user_tables.relid
+		&t.name,             // pg_stat_user_tables.relname
+		&t.namespace,        // pg_stat_user_tables.schemaname
+		&t.numInserts,       // pg_stat_user_tables.n_tup_ins
+		&t.numUpdates,       // pg_stat_user_tables.n_tup_upd
+		&t.numHotUpdates,    // pg_stat_user_tables.n_tup_hot_upd
+		&t.numDeletes,       // pg_stat_user_tables.n_tup_del
+		&t.numLiveRows,      // pg_stat_user_tables.n_live_tup
+		&t.heapSize,         // pg_table_size(relid)
+		&t.totalSize,        // pg_total_relation_size(relid)
+		&t.fillFactor,       // pg_class.reloptions fillfactor, default 100
+		&t.rowSecurity,      // pg_class.relrowsecurity
+		&t.forceRowSecurity, // pg_class.relforcerowsecurity
+		&t.parentOID,        // pg_inherits.inhparent
+		&t.parentName,       // pg_class[2].relname (parent)
+		&t.partitioned,      // pg_class.relkind = 'p'
+	)
+}
+
+// TablesByOID maps each table's OID to the table.
+func TablesByOID(tables []*Table) map[pgtype.OID]*Table {
+	m := make(map[pgtype.OID]*Table, len(tables))
+	for _, t := range tables {
+		m[t.oid] = t
+	}
+	return m
+}
@@ -1,4 +1,4 @@
-package main
+package catalog
 
 import (
 	"fmt"
@@ -42,22 +42,21 @@ func (b Bytes) Human() string {
 	return strconv.Itoa(int(b)) + " B"
 }
 
-// oidVector corresponds to the Postgres type "oidvector".
+// OIDVector corresponds to the Postgres type "oidvector".
 //
 // Neither the binary nor the text representation of an oidvector
 // appears to be documented, but the text representation seems
 // straightforward: a sequence of integer strings, separated by
 // whitespace.
-//
-type oidVector []pgtype.OID
+type OIDVector []pgtype.OID
 
 // DecodeText is part of the TextDecoder interface.
-func (vec *oidVector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+func (vec *OIDVector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
 	if src == nil {
 		return nil // vector is empty
 	}
 	fields := strings.Fields(string(src))
-	*vec = make(oidVector, len(fields))
+	*vec = make(OIDVector, len(fields))
 	for i, s := range fields {
 		// N.B. OID is an unsigned 32-bit int, so we decode its string
 		// repr as a 64-bit signed int to prevent overflow.
@@ -70,8 +69,8 @@ func (vec *oidVector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
 	return nil
 }
 
-// Reports whether two oidVectors contain the same values.
-func (vec oidVector) equal(rhs oidVector) bool {
+// Equal reports whether two OIDVectors contain the same values.
+func (vec OIDVector) Equal(rhs OIDVector) bool {
 	if len(vec) != len(rhs) {
 		return false
 	}
@@ -83,16 +82,16 @@ func (vec oidVector) equal(rhs oidVector) bool {
 	return true
 }
 
-// int2Vector corresponds to the Postgres type "int2vector".
-type int2Vector []int16
+// Int2Vector corresponds to the Postgres type "int2vector".
+type Int2Vector []int16
 
 // DecodeText is part of the TextDecoder interface.
-func (vec *int2Vector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+func (vec *Int2Vector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
 	if src == nil {
 		return nil // vector is empty
 	}
 	fields := strings.Fields(string(src))
-	*vec = make(int2Vector, len(fields))
+	*vec = make(Int2Vector, len(fields))
 	for i, s := range fields {
 		n, err := strconv.ParseInt(s, 10, 16)
 		if err != nil {
@@ -103,8 +102,8 @@ func (vec *int2Vector) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
 	return nil
 }
 
-// Reports whether two int2Vectors contain the same values.
-func (vec int2Vector) equal(rhs int2Vector) bool {
+// Equal reports whether two Int2Vectors contain the same values.
+func (vec Int2Vector) Equal(rhs Int2Vector) bool {
 	if len(vec) != len(rhs) {
 		return false
 	}
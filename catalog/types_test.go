@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+func TestOIDVectorDecodeText(t *testing.T) {
+	tests := []struct {
+		src     []byte
+		want    OIDVector
+		wantErr bool
+	}{
+		{nil, nil, false},
+		{[]byte(""), OIDVector{}, false},
+		{[]byte("1978"), OIDVector{1978}, false},
+		{[]byte("1978 3126  100"), OIDVector{1978, 3126, 100}, false},
+		{[]byte("4294967295"), OIDVector{4294967295}, false}, // max OID
+		{[]byte("12 x"), nil, true},
+	}
+	for _, tt := range tests {
+		var vec OIDVector
+		err := vec.DecodeText(nil, tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DecodeText(%q): err = %v, wantErr %v", tt.src, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !vec.Equal(tt.want) {
+			t.Errorf("DecodeText(%q) = %v, want %v", tt.src, vec, tt.want)
+		}
+	}
+}
+
+func TestInt2VectorDecodeText(t *testing.T) {
+	tests := []struct {
+		src     []byte
+		want    Int2Vector
+		wantErr bool
+	}{
+		{nil, nil, false},
+		{[]byte("1"), Int2Vector{1}, false},
+		{[]byte("2 0 3"), Int2Vector{2, 0, 3}, false},
+		{[]byte("-1"), Int2Vector{-1}, false},
+		{[]byte("32768"), nil, true}, // overflows int16
+		{[]byte("1,2"), nil, true},
+	}
+	for _, tt := range tests {
+		var vec Int2Vector
+		err := vec.DecodeText(nil, tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DecodeText(%q): err = %v, wantErr %v", tt.src, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !vec.Equal(tt.want) {
+			t.Errorf("DecodeText(%q) = %v, want %v", tt.src, vec, tt.want)
+		}
+	}
+}
+
+func TestVectorEqual(t *testing.T) {
+	if !(OIDVector{1, 2}).Equal(OIDVector{1, 2}) {
+		t.Error("equal OIDVectors reported unequal")
+	}
+	if (OIDVector{1, 2}).Equal(OIDVector{2, 1}) {
+		t.Error("OIDVectors in different order reported equal")
+	}
+	if (OIDVector{pgtype.OID(1)}).Equal(OIDVector{1, 2}) {
+		t.Error("OIDVectors of different lengths reported equal")
+	}
+	if !(Int2Vector{}).Equal(nil) {
+		t.Error("empty and nil Int2Vectors reported unequal")
+	}
+}
+
+func TestBytesHuman(t *testing.T) {
+	tests := []struct {
+		b    Bytes
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{KiB, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{10 * MiB, "10.0 MiB"},
+		{3 * GiB / 2, "1.5 GiB"},
+		{2 * TiB, "2.0 TiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Human(); got != tt.want {
+			t.Errorf("Bytes(%d).Human() = %q, want %q", int64(tt.b), got, tt.want)
+		}
+	}
+}
+
+func TestSettingBytes(t *testing.T) {
+	tests := []struct {
+		setting, unit string
+		want          Bytes
+	}{
+		{"16384", "8kB", 128 * MiB},
+		{"65536", "kB", 64 * MiB},
+		{"16", "MB", 16 * MiB},
+		{"1024", "B", KiB},
+		{"4", "", 0},
+		{"-1", "ms", 0},
+	}
+	for _, tt := range tests {
+		s := &Setting{setting: tt.setting, unit: tt.unit}
+		if got := s.Bytes(); got != tt.want {
+			t.Errorf("Setting{%s %s}.Bytes() = %d, want %d", tt.setting, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx"
+)
+
+// MinVersion is the oldest Postgres release pglint supports.
+const MinVersion Version = 90600
+
+// Version is a Postgres release number in server_version_num format, e.g.
+// 90624 for 9.6.24 or 130002 for 13.2.
+type Version int
+
+// String formats the version as Postgres does, e.g. "9.6.24" or "13.2".
+func (v Version) String() string {
+	if v < 100000 {
+		return fmt.Sprintf("%d.%d.%d", v/10000, v/100%100, v%100)
+	}
+	return fmt.Sprintf("%d.%d", v/10000, v%10000)
+}
+
+// Asks the server for its version.
+func queryServerVersion(ctx context.Context, tx *pgx.Tx) (Version, error) {
+	var s string
+	if err := tx.QueryRowEx(ctx, "select current_setting('server_version_num')", nil).Scan(&s); err != nil {
+		return 0, fmt.Errorf("querying server version: %v", err)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid server_version_num %q: %v", s, err)
+	}
+	return Version(n), nil
+}
+
+// catalogQuery is a catalog query with one SQL variant per range of server
+// versions, since the system catalogs change between Postgres releases.
+type catalogQuery struct {
+	name     string       // describes the query in error messages
+	variants []sqlVariant // in descending order of minVersion
+}
+
+// sqlVariant is the SQL of a catalog query for a range of server versions.
+type sqlVariant struct {
+	minVersion Version // oldest version on which sql works
+	sql        string
+}
+
+// Returns the SQL appropriate for the server version. If the query is not
+// supported on that version, returns an *UnsupportedError.
+func (q *catalogQuery) forVersion(v Version) (string, error) {
+	for _, variant := range q.variants {
+		if v >= variant.minVersion {
+			return variant.sql, nil
+		}
+	}
+	oldest := q.variants[len(q.variants)-1].minVersion
+	return "", &UnsupportedError{What: q.name, Version: v, MinVersion: oldest}
+}
+
+// UnsupportedError reports that a query or check cannot be run on the
+// connected server's version.
+type UnsupportedError struct {
+	What       string  // the unsupported query or check
+	Version    Version // the server's version
+	MinVersion Version // the oldest version that supports What
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s requires Postgres %s or later, but the server is running %s",
+		e.What, e.MinVersion, e.Version)
+}
@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionString(t *testing.T) {
+	tests := []struct {
+		v    Version
+		want string
+	}{
+		{90624, "9.6.24"},
+		{100000, "10.0"},
+		{130002, "13.2"},
+		{160004, "16.4"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("Version(%d).String() = %q, want %q", int(tt.v), got, tt.want)
+		}
+	}
+}
+
+func TestCatalogQueryForVersion(t *testing.T) {
+	q := &catalogQuery{
+		name: "test query",
+		variants: []sqlVariant{
+			{110000, "new"},
+			{100000, "old"},
+		},
+	}
+	tests := []struct {
+		v    Version
+		want string
+	}{
+		{160000, "new"},
+		{110000, "new"},
+		{100007, "old"},
+	}
+	for _, tt := range tests {
+		got, err := q.forVersion(tt.v)
+		if err != nil || got != tt.want {
+			t.Errorf("forVersion(%d) = %q, %v; want %q", int(tt.v), got, err, tt.want)
+		}
+	}
+	_, err := q.forVersion(90624)
+	if e, ok := err.(*UnsupportedError); !ok || e.MinVersion != 100000 {
+		t.Errorf("forVersion(90624): got error %v, want *UnsupportedError", err)
+	}
+}
+
+func TestForeignKeysOmitClones(t *testing.T) {
+	// A key cloned into a partition has a parent; conparentid is new in 11.
+	for _, tt := range []struct {
+		v    Version
+		want bool
+	}{{110000, true}, {150004, true}, {100012, false}, {90624, false}} {
+		sql, err := sqlSelectForeignKeys.forVersion(tt.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(sql, "c.conparentid = 0"); got != tt.want {
+			t.Errorf("Postgres %s: omits cloned foreign keys = %v, want %v", tt.v, got, tt.want)
+		}
+		if !tt.want && strings.Contains(sql, "conparentid") {
+			t.Errorf("Postgres %s: query uses conparentid", tt.v)
+		}
+	}
+}
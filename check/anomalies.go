@@ -0,0 +1,160 @@
+// Package check analyzes the indexes loaded by package catalog and reports
+// anomalies such as duplicate, redundant, unused and invalid indexes.
+package check
+
+import (
+	"context"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// A DuplicateSet is a set of two or more structurally equivalent indexes. All
+// but one index in the set is superfluous.
+type DuplicateSet []*catalog.Index
+
+// A RedundantPair is a pair of indexes where Index is made redundant by
+// Covering: Index's attributes are a strict prefix of Covering's.
+type RedundantPair struct {
+	Index    *catalog.Index // the redundant index
+	Covering *catalog.Index // the index that makes it redundant
+}
+
+// DuplicateIndexSets finds indexes that are exact duplicates of one another
+// and groups them into sets. Partition indexes are rolled up into their
+// partitioned indexes first.
+func DuplicateIndexSets(ctx context.Context, src catalog.Source) ([]DuplicateSet, error) {
+	indexes, err := logicalIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	var answer []DuplicateSet
+	for len(indexes) > 0 {
+		duplicates, rest := bisectIndexes(indexes, indexes[0].EquivalentTo)
+		if len(duplicates) >= 2 {
+			answer = append(answer, duplicates)
+		}
+		indexes = rest
+	}
+	return answer, nil
+}
+
+// UnusedIndexes returns indexes whose statistics indicate they have been
+// scanned at most cutoff times. Such indexes are possibly superfluous. The
+// scans of a partitioned index are those of all its partitions' indexes.
+func UnusedIndexes(ctx context.Context, src catalog.Source, cutoff int) ([]*catalog.Index, error) {
+	indexes, err := logicalIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.FilterIndexes(indexes, func(ind *catalog.Index) bool {
+		return ind.NumScans() <= cutoff
+	}), nil
+}
+
+// InvalidIndexes returns indexes that cannot be used by queries because they
+// are invalid, not ready or being dropped. They nevertheless consume disk
+// space and, unless they are being dropped, slow down writes.
+func InvalidIndexes(ctx context.Context, src catalog.Source) ([]*catalog.Index, error) {
+	return src.InvalidIndexes(ctx)
+}
+
+// RedundantIndexPairs returns the pairs of indexes where the first index in
+// the pair is made redundant by the second. Partition indexes are rolled up
+// into their partitioned indexes first.
+func RedundantIndexPairs(ctx context.Context, src catalog.Source) ([]RedundantPair, error) {
+	indexes, err := logicalIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the indexes by table so that small sets can be compared.
+	indexesByTable := make(map[pgtype.OID][]*catalog.Index)
+	for _, ind := range indexes {
+		if !ind.IsPrimary() && !ind.IsUnique() {
+			indexesByTable[ind.TableOID()] = append(indexesByTable[ind.TableOID()], ind)
+		}
+	}
+
+	// For each unique pair of indexes within a table, test whether the
+	// first is redundant w/r/t the second. If so, append to answer.
+	var answer []RedundantPair
+	for _, indexes := range indexesByTable {
+		for _, ind1 := range indexes {
+			for _, ind2 := range indexes {
+				if ind1 != ind2 && IsRedundantIndex(ind1, ind2) {
+					answer = append(answer, RedundantPair{ind1, ind2})
+					break // next index
+				}
+			}
+		}
+	}
+	return answer, nil
+}
+
+// Returns the valid, live indexes in src, with partition indexes rolled up
+// into their partitioned indexes; q.v. catalog.RollUpPartitions.
+func logicalIndexes(ctx context.Context, src catalog.Source) ([]*catalog.Index, error) {
+	indexes, err := src.AllIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.RollUpPartitions(indexes), nil
+}
+
+// Returns the OID of the table at the top of the partition hierarchy of the
+// table with the given OID, according to roots (q.v. catalog.PartitionRoots),
+// or the OID itself if the table is unknown.
+func rootOf(roots map[pgtype.OID]pgtype.OID, oid pgtype.OID) pgtype.OID {
+	if root, ok := roots[oid]; ok {
+		return root
+	}
+	return oid
+}
+
+// IsRedundantIndex reports whether ind1 is redundant w/r/t ind2, which means
+// all of the following are true: ind1's attributes are a strict prefix of
+// ind2's key attributes; they have identical predicates; they are either both
+// unique or both non-unique.
+func IsRedundantIndex(ind1, ind2 *catalog.Index) bool {
+	return ind1.IsUnique() == ind2.IsUnique() && ind1.Pred() == ind2.Pred() && prefixOf(ind1, ind2)
+}
+
+// Reports whether ind1's attributes are a strict prefix of ind2's key
+// attributes. For example, if ind1 were an index on "X, Y" and ind2 on "X, Y,
+// Z", ind1 would be a prefix of ind2 (but not if ind2 were also on "X, Y", or
+// on "X" INCLUDE "Y, Z", whose included columns cannot be searched). An index
+// with INCLUDE columns is never a prefix, since ind2 might not return them.
+func prefixOf(ind1, ind2 *catalog.Index) bool {
+	attrs1 := ind1.Attrs()
+	attrs2 := ind2.KeyAttrs()
+	if len(ind1.KeyAttrs()) < len(attrs1) {
+		return false // ind1 has INCLUDE columns
+	}
+	if len(attrs1) >= len(attrs2) {
+		return false // a must have fewer attributes than b
+	}
+	for i, x := range attrs1 {
+		y := attrs2[i]
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+// bisectIndexes returns two slices: the first contains values in xs for which
+// pred returns true; the second contains the other values. N.B. modifies xs in
+// place; the two returned slices are subslices of xs.
+func bisectIndexes(xs []*catalog.Index, pred func(*catalog.Index) bool) ([]*catalog.Index, []*catalog.Index) {
+	n := len(xs)
+	for i := 0; i < n; {
+		if pred(xs[i]) {
+			xs[i], xs[n-1] = xs[n-1], xs[i]
+			n--
+		} else {
+			i++
+		}
+	}
+	return xs[n:], xs[:n]
+}
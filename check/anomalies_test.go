@@ -0,0 +1,151 @@
+package check
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// Loads a fixture from the testdata directory.
+func loadFixture(t *testing.T, name string) *catalog.Snapshot {
+	t.Helper()
+	snap, err := catalog.ReadSnapshot(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
+
+// Returns the sorted names of the indexes.
+func indexNames(indexes []*catalog.Index) []string {
+	var names []string
+	for _, ind := range indexes {
+		names = append(names, ind.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDuplicateIndexSets(t *testing.T) {
+	sets, err := DuplicateIndexSets(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("got %d duplicate sets, want 1", len(sets))
+	}
+	want := []string{"users_email_idx", "users_email_idx2"}
+	if got := indexNames(sets[0]); !equalStrings(got, want) {
+		t.Errorf("got duplicates %v, want %v", got, want)
+	}
+}
+
+func TestRedundantIndexPairs(t *testing.T) {
+	pairs, err := RedundantIndexPairs(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pair := range pairs {
+		got = append(got, pair.Index.Name()+" < "+pair.Covering.Name())
+	}
+	sort.Strings(got)
+	want := []string{
+		"users_email_idx < users_email_name_idx",
+		"users_email_idx2 < users_email_name_idx",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("got redundant pairs %v, want %v", got, want)
+	}
+}
+
+func TestUnusedIndexes(t *testing.T) {
+	tests := []struct {
+		cutoff int
+		want   []string
+	}{
+		{0, []string{"users_lower_email_idx"}},
+		{10, []string{"orders_pkey", "users_email_idx", "users_lower_email_idx"}},
+		{1000, []string{"orders_pkey", "orders_user_id_created_at_idx", "users_active_idx", "users_email_idx",
+			"users_email_idx2", "users_email_name_idx", "users_lower_email_idx"}},
+	}
+	src := loadFixture(t, "indexes.json")
+	for _, tt := range tests {
+		unused, err := UnusedIndexes(context.Background(), src, tt.cutoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := indexNames(unused); !equalStrings(got, tt.want) {
+			t.Errorf("cutoff %d: got %v, want %v", tt.cutoff, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidIndexes(t *testing.T) {
+	invalid, err := InvalidIndexes(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"orders_status_idx"}
+	if got := indexNames(invalid); !equalStrings(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestIsRedundantIndexRequiresStrictPrefix(t *testing.T) {
+	src := loadFixture(t, "indexes.json")
+	byName := make(map[string]*catalog.Index)
+	for _, ind := range src.Indexes {
+		byName[ind.Name()] = ind
+	}
+	tests := []struct {
+		ind1, ind2 string
+		want       bool
+	}{
+		{"users_email_idx", "users_email_name_idx", true},
+		{"users_email_name_idx", "users_email_idx", false},
+		{"users_email_idx", "users_email_idx2", false},                 // equal, not a strict prefix
+		{"orders_user_id_idx", "orders_user_id_created_at_idx", false}, // predicates differ
+		{"users_lower_email_idx", "users_email_name_idx", false},       // uniqueness differs
+	}
+	for _, tt := range tests {
+		if got := IsRedundantIndex(byName[tt.ind1], byName[tt.ind2]); got != tt.want {
+			t.Errorf("IsRedundantIndex(%s, %s) = %v, want %v", tt.ind1, tt.ind2, got, tt.want)
+		}
+	}
+
+	// Only key columns can be searched, so INCLUDE columns are not part of the
+	// prefix; and an index with INCLUDE columns has no redundant prefix.
+	for _, ind := range loadFixture(t, "include.json").Indexes {
+		byName[ind.Name()] = ind
+	}
+	tests = []struct {
+		ind1, ind2 string
+		want       bool
+	}{
+		{"orders_user_id_status_idx", "orders_user_id_covering_idx", false},
+		{"orders_user_id_idx", "orders_user_id_covering_idx", false},
+		{"orders_user_id_idx", "orders_user_id_status_covering_idx", true},
+		{"orders_user_id_covering_idx", "orders_user_id_status_covering_idx", false},
+	}
+	for _, tt := range tests {
+		if got := IsRedundantIndex(byName[tt.ind1], byName[tt.ind2]); got != tt.want {
+			t.Errorf("IsRedundantIndex(%s, %s) = %v, want %v", tt.ind1, tt.ind2, got, tt.want)
+		}
+	}
+}
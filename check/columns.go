@@ -0,0 +1,148 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// ColumnOptions configure the column type rules.
+type ColumnOptions struct {
+	Disabled       map[string]bool // names of the rules to skip
+	MaxTinyVarchar int             // varchar(n) is reported if n is at most this
+	LargeTableRows int             // int4 primary keys are reported on tables with this many rows or inserts
+}
+
+// A ColumnFinding is a column whose type breaks one of the column rules.
+type ColumnFinding struct {
+	Column *catalog.TableColumn
+	Rule   string // the rule's name
+	Advice string
+}
+
+// A columnRule reports whether a column's type is questionable.
+type columnRule struct {
+	name       string
+	advice     string
+	minVersion catalog.Version // the advice needs this server version or later; zero for any
+	match      func(c *catalog.TableColumn, t *catalog.Table, opts ColumnOptions) bool
+}
+
+var columnRules = []columnRule{
+	{
+		name:   "timestamp",
+		advice: "use timestamp with time zone, which stores an unambiguous point in time",
+		match: func(c *catalog.TableColumn, _ *catalog.Table, _ ColumnOptions) bool {
+			return c.TypeName() == "timestamp"
+		},
+	},
+	{
+		name:   "money",
+		advice: "use numeric, and store the currency separately; money's output depends on lc_monetary",
+		match: func(c *catalog.TableColumn, _ *catalog.Table, _ ColumnOptions) bool {
+			return c.TypeName() == "money"
+		},
+	},
+	{
+		name:   "json",
+		advice: "use jsonb, which can be indexed and compared, and is faster to process",
+		match: func(c *catalog.TableColumn, _ *catalog.Table, _ ColumnOptions) bool {
+			return c.TypeName() == "json"
+		},
+	},
+	{
+		name:   "char",
+		advice: "use text or varchar; char(n) pads values with spaces and is no faster",
+		match: func(c *catalog.TableColumn, _ *catalog.Table, _ ColumnOptions) bool {
+			return c.TypeName() == "bpchar"
+		},
+	},
+	{
+		name:   "varchar",
+		advice: "use text, perhaps with a check constraint; changing a tiny limit later rewrites the table",
+		match: func(c *catalog.TableColumn, _ *catalog.Table, opts ColumnOptions) bool {
+			return c.TypeName() == "varchar" && c.Length() >= 0 && c.Length() <= opts.MaxTinyVarchar
+		},
+	},
+	{
+		name:   "int4-pk",
+		advice: "use bigint before the key runs out of values; the change rewrites the table",
+		match: func(c *catalog.TableColumn, t *catalog.Table, opts ColumnOptions) bool {
+			if !c.InPrimaryKey() || (c.TypeName() != "int4" && c.TypeName() != "int2") || t == nil {
+				return false
+			}
+			return t.NumLiveRows() >= opts.LargeTableRows || t.NumInserts() >= opts.LargeTableRows
+		},
+	},
+	{
+		name:       "serial",
+		advice:     "use an identity column, which is standard SQL and owns its sequence",
+		minVersion: 100000,
+		match: func(c *catalog.TableColumn, _ *catalog.Table, _ ColumnOptions) bool {
+			return c.IsSerial()
+		},
+	},
+}
+
+// ColumnRuleNames returns the names of the column rules, in the order they
+// are applied.
+func ColumnRuleNames() []string {
+	names := make([]string, len(columnRules))
+	for i, rule := range columnRules {
+		names[i] = rule.name
+	}
+	return names
+}
+
+// ColumnTypes applies the column rules that are not disabled to every column,
+// returning the findings sorted by column. A rule whose advice the server's
+// version cannot follow is skipped, unless the version is unknown.
+func ColumnTypes(ctx context.Context, src catalog.Source, opts ColumnOptions) ([]ColumnFinding, error) {
+	for name := range opts.Disabled {
+		if !isColumnRule(name) {
+			return nil, fmt.Errorf("unknown column rule %q", name)
+		}
+	}
+	columns, err := src.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := src.AllTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Partitions are rolled up, so that the int4-pk rule judges a partitioned
+	// table by the rows of all its partitions.
+	tablesByOID := catalog.TablesByOID(catalog.RollUpTables(tables))
+	version := src.ServerVersion()
+	var rules []columnRule
+	for _, rule := range columnRules {
+		if !opts.Disabled[rule.name] && (version == 0 || version >= rule.minVersion) {
+			rules = append(rules, rule)
+		}
+	}
+	var answer []ColumnFinding
+	for _, c := range columns {
+		for _, rule := range rules {
+			if rule.match(c, tablesByOID[c.TableOID()], opts) {
+				answer = append(answer, ColumnFinding{c, rule.name, rule.advice})
+			}
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Column.QualifiedName() < answer[j].Column.QualifiedName()
+	})
+	return answer, nil
+}
+
+// Reports whether name is the name of a column rule.
+func isColumnRule(name string) bool {
+	for _, rule := range columnRules {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColumnTypes(t *testing.T) {
+	src := loadFixture(t, "indexes.json")
+	all := []string{
+		"orders.currency varchar",
+		"orders.id int4-pk",
+		"orders.id serial",
+		"orders.status char",
+		"orders.total money",
+		"users.created_at timestamp",
+		"users.settings json",
+	}
+	tests := []struct {
+		opts ColumnOptions
+		want []string
+	}{
+		{ColumnOptions{MaxTinyVarchar: 5, LargeTableRows: 50000}, all},
+		{ColumnOptions{MaxTinyVarchar: 2, LargeTableRows: 100000}, []string{
+			"orders.id serial",
+			"orders.status char",
+			"orders.total money",
+			"users.created_at timestamp",
+			"users.settings json",
+		}},
+		{ColumnOptions{
+			Disabled:       map[string]bool{"serial": true, "json": true, "timestamp": true},
+			MaxTinyVarchar: 5,
+			LargeTableRows: 50000,
+		}, []string{
+			"orders.currency varchar",
+			"orders.id int4-pk",
+			"orders.status char",
+			"orders.total money",
+		}},
+	}
+	for _, tt := range tests {
+		findings, err := ColumnTypes(context.Background(), src, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.Column.QualifiedName()+" "+f.Rule)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("%+v: got %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestColumnTypesOldServer(t *testing.T) {
+	// Identity columns, which the serial rule recommends, need Postgres 10.
+	src := loadFixture(t, "indexes.json")
+	src.ServerVersionNum = 90624
+	findings, err := ColumnTypes(context.Background(), src, ColumnOptions{MaxTinyVarchar: 5, LargeTableRows: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Rule == "serial" {
+			t.Errorf("got serial finding for %s on Postgres 9.6", f.Column.QualifiedName())
+		}
+	}
+	if len(findings) != 6 {
+		t.Errorf("got %d findings, want 6", len(findings))
+	}
+}
+
+func TestColumnTypesPartitioned(t *testing.T) {
+	// Neither partition has 100000 rows, but together they do.
+	src := loadFixture(t, "partitions.json")
+	findings, err := ColumnTypes(context.Background(), src, ColumnOptions{MaxTinyVarchar: 5, LargeTableRows: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Column.QualifiedName()+" "+f.Rule)
+	}
+	if want := []string{"events.created_at timestamp", "events.id int4-pk"}; !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestColumnTypesUnknownRule(t *testing.T) {
+	opts := ColumnOptions{Disabled: map[string]bool{"nosuchrule": true}}
+	if _, err := ColumnTypes(context.Background(), loadFixture(t, "indexes.json"), opts); err == nil {
+		t.Error("got no error for an unknown rule")
+	}
+}
@@ -0,0 +1,85 @@
+package check
+
+import "github.com/dcowgill/pglint/catalog"
+
+// SchemaDrift describes the differences between the index catalogs of two
+// databases, conventionally called the left and right sides.
+type SchemaDrift struct {
+	MissingOnRight []*catalog.Index // indexes on the left that the right lacks
+	MissingOnLeft  []*catalog.Index // indexes on the right that the left lacks
+	Changed        []IndexDiff      // same name, different definition
+	InvalidOnLeft  []IndexDiff      // invalid on the left only; Right may be nil
+	InvalidOnRight []IndexDiff      // invalid on the right only; Left may be nil
+}
+
+// An IndexDiff pairs two versions of the same index, one from each side of a
+// comparison. One of them may be nil if the index exists on one side only.
+type IndexDiff struct {
+	Left, Right *catalog.Index
+}
+
+// Either returns d.Left if it is non-nil, otherwise d.Right.
+func (d IndexDiff) Either() *catalog.Index {
+	if d.Left != nil {
+		return d.Left
+	}
+	return d.Right
+}
+
+// FindSchemaDrift compares two sets of indexes, which should include invalid
+// ones, and reports how they differ. Indexes are matched by qualified name;
+// two indexes with the same name are considered different if they are not
+// equivalent according to Index.EquivalentAcross.
+func FindSchemaDrift(left, right []*catalog.Index) *SchemaDrift {
+	var (
+		drift   = new(SchemaDrift)
+		leftBy  = indexesByQualifiedName(left)
+		rightBy = indexesByQualifiedName(right)
+	)
+	for name, l := range leftBy {
+		r, ok := rightBy[name]
+		if !ok {
+			drift.MissingOnRight = append(drift.MissingOnRight, l)
+			if !l.Usable() {
+				drift.InvalidOnLeft = append(drift.InvalidOnLeft, IndexDiff{l, nil})
+			}
+			continue
+		}
+		if !l.EquivalentAcross(r) {
+			drift.Changed = append(drift.Changed, IndexDiff{l, r})
+		}
+		switch {
+		case !l.Usable() && r.Usable():
+			drift.InvalidOnLeft = append(drift.InvalidOnLeft, IndexDiff{l, r})
+		case l.Usable() && !r.Usable():
+			drift.InvalidOnRight = append(drift.InvalidOnRight, IndexDiff{l, r})
+		}
+	}
+	for name, r := range rightBy {
+		if _, ok := leftBy[name]; !ok {
+			drift.MissingOnLeft = append(drift.MissingOnLeft, r)
+			if !r.Usable() {
+				drift.InvalidOnRight = append(drift.InvalidOnRight, IndexDiff{nil, r})
+			}
+		}
+	}
+	return drift
+}
+
+// Maps each index's fully qualified name to the index.
+func indexesByQualifiedName(indexes []*catalog.Index) map[string]*catalog.Index {
+	m := make(map[string]*catalog.Index, len(indexes))
+	for _, ind := range indexes {
+		m[ind.Namespace()+"."+ind.Name()] = ind
+	}
+	return m
+}
+
+// Empty reports whether the two sides are identical.
+func (d *SchemaDrift) Empty() bool {
+	return (len(d.MissingOnRight) == 0 &&
+		len(d.MissingOnLeft) == 0 &&
+		len(d.Changed) == 0 &&
+		len(d.InvalidOnLeft) == 0 &&
+		len(d.InvalidOnRight) == 0)
+}
@@ -0,0 +1,63 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+func TestFindSchemaDrift(t *testing.T) {
+	ctx := context.Background()
+	left, err := loadFixture(t, "indexes.json").EveryIndex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	right, err := loadFixture(t, "drift_right.json").EveryIndex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drift := FindSchemaDrift(left, right)
+
+	diffNames := func(diffs []IndexDiff) []string {
+		var indexes []*catalog.Index
+		for _, d := range diffs {
+			indexes = append(indexes, d.Either())
+		}
+		return indexNames(indexes)
+	}
+	tests := []struct {
+		what string
+		got  []string
+		want []string
+	}{
+		{"missing on right", indexNames(drift.MissingOnRight), []string{
+			"orders_pkey", "orders_user_id_created_at_idx", "orders_user_id_idx",
+			"users_active_idx", "users_email_idx", "users_email_idx2", "users_lower_email_idx",
+		}},
+		{"missing on left", indexNames(drift.MissingOnLeft), []string{"users_name_idx"}},
+		{"changed", diffNames(drift.Changed), []string{"users_email_name_idx"}},
+		{"invalid on left", diffNames(drift.InvalidOnLeft), []string{"orders_status_idx"}},
+		{"invalid on right", diffNames(drift.InvalidOnRight), nil},
+	}
+	for _, tt := range tests {
+		if !equalStrings(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.what, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFindSchemaDriftIdentical(t *testing.T) {
+	ctx := context.Background()
+	left, err := loadFixture(t, "indexes.json").EveryIndex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	right, err := loadFixture(t, "indexes.json").EveryIndex(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift := FindSchemaDrift(left, right); !drift.Empty() {
+		t.Errorf("comparing a fixture with itself: got %+v, want no drift", drift)
+	}
+}
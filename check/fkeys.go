@@ -0,0 +1,56 @@
+package check
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// An FKMismatch is a pair of foreign key columns whose definitions differ.
+// Problems lists how: "type", "collation" or "nullability".
+type FKMismatch struct {
+	ForeignKey *catalog.ForeignKey
+	Columns    catalog.FKColumns
+	Problems   []string
+}
+
+// ForeignKeyMismatches returns the foreign key columns whose type, collation
+// or nullability differs from that of the column they reference. A narrower
+// referencing type, e.g. integer referencing bigint, fails once the referenced
+// values outgrow it; any difference in type or collation can stop the planner
+// from using an index for joins. A nullable column referencing a NOT NULL one
+// is often an oversight, and in a multi-column MATCH SIMPLE foreign key, a row
+// in which only some of the columns are null is not checked at all.
+func ForeignKeyMismatches(ctx context.Context, src catalog.Source) ([]FKMismatch, error) {
+	fkeys, err := src.ForeignKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var answer []FKMismatch
+	for _, fk := range fkeys {
+		for _, cols := range fk.Columns() {
+			var problems []string
+			if cols.Column.Type != cols.RefColumn.Type {
+				problems = append(problems, "type")
+			}
+			if cols.Column.Collation != cols.RefColumn.Collation {
+				problems = append(problems, "collation")
+			}
+			if cols.Column.NotNull != cols.RefColumn.NotNull {
+				problems = append(problems, "nullability")
+			}
+			if len(problems) != 0 {
+				answer = append(answer, FKMismatch{fk, cols, problems})
+			}
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		fk1, fk2 := answer[i].ForeignKey, answer[j].ForeignKey
+		if fk1.QualifiedTableName() != fk2.QualifiedTableName() {
+			return fk1.QualifiedTableName() < fk2.QualifiedTableName()
+		}
+		return fk1.Name() < fk2.Name()
+	})
+	return answer, nil
+}
@@ -0,0 +1,28 @@
+package check
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestForeignKeyMismatches(t *testing.T) {
+	mismatches, err := ForeignKeyMismatches(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range mismatches {
+		got = append(got, m.ForeignKey.Name()+"."+m.Columns.Column.Name+": "+strings.Join(m.Problems, ", "))
+	}
+	// order_items_order_id_fkey is a single-column foreign key whose nullable
+	// column references a NOT NULL one.
+	want := []string{
+		"order_items_order_id_fkey.order_id: nullability",
+		"orders_user_email_fkey.email: collation, nullability",
+		"orders_user_id_fkey.user_id: type",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
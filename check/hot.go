@@ -0,0 +1,170 @@
+package check
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// DefaultUpdatedColumns matches the names of columns that are typically
+// modified after a row is inserted, such as timestamps and status flags.
+const DefaultUpdatedColumns = `^(updated|modified|changed|deleted)(_at|_on|_time|_date)?$|^last_|^(status|state)$|_(status|state|count)$`
+
+// HotOptions determine which tables HotBlockers reports.
+type HotOptions struct {
+	MinHotRatio    float64        // tables with a lower HOT update ratio are reported
+	MinUpdates     int            // tables with fewer updates are ignored
+	UpdatedColumns *regexp.Regexp // names of columns that are likely updated; nil for DefaultUpdatedColumns
+}
+
+var defaultUpdatedColumns = regexp.MustCompile(DefaultUpdatedColumns)
+
+// A HotBlocker is a table whose updates are seldom heap-only tuple (HOT)
+// updates. An update can only be HOT if it modifies no indexed column and the
+// new row version fits on the same heap page. Indexes lists the indexes that
+// cover columns which are likely updated, and Columns those columns.
+type HotBlocker struct {
+	Table   *catalog.Table
+	Indexes []*catalog.Index
+	Columns []string
+}
+
+// HotBlockers returns the tables whose HOT update ratio is below the minimum,
+// along with the indexes that likely prevent HOT updates. A column the
+// planner's statistics show to be always null is not considered updated.
+// Partitions and their indexes are rolled up into their partitioned tables and
+// indexes first.
+func HotBlockers(ctx context.Context, src catalog.Source, opts HotOptions) ([]HotBlocker, error) {
+	tables, err := src.AllTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Every index maintained on writes blocks HOT updates, valid or not.
+	indexes, err := src.EveryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roots := catalog.PartitionRoots(tables)
+	tables, indexes = catalog.RollUpTables(tables), catalog.RollUpPartitions(indexes)
+	stats, err := src.ColumnStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updatedColumns := opts.UpdatedColumns
+	if updatedColumns == nil {
+		updatedColumns = defaultUpdatedColumns
+	}
+	statsByName := catalog.ColumnStatsByName(stats)
+	indexesByTable := make(map[pgtype.OID][]*catalog.Index)
+	for _, ind := range indexes {
+		if ind.IsReady() && ind.IsLive() {
+			root := rootOf(roots, ind.TableOID())
+			indexesByTable[root] = append(indexesByTable[root], ind)
+		}
+	}
+
+	var answer []HotBlocker
+	for _, t := range tables {
+		if t.NumUpdates() < opts.MinUpdates || t.HotUpdateRatio() >= opts.MinHotRatio {
+			continue
+		}
+		b := HotBlocker{Table: t}
+		seen := make(map[string]bool)
+		for _, ind := range indexesByTable[t.OID()] {
+			blocks := false
+			for _, col := range IndexColumns(ind) {
+				if !updatedColumns.MatchString(col) {
+					continue
+				}
+				if s := statsByName[catalog.ColumnStatsKey{Table: t.Name(), Column: col}]; s != nil && s.NullFrac() >= 1 {
+					continue
+				}
+				blocks = true
+				if !seen[col] {
+					seen[col] = true
+					b.Columns = append(b.Columns, col)
+				}
+			}
+			if blocks {
+				b.Indexes = append(b.Indexes, ind)
+			}
+		}
+		sort.Strings(b.Columns)
+		answer = append(answer, b)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Table.HotUpdateRatio() < answer[j].Table.HotUpdateRatio()
+	})
+	return answer, nil
+}
+
+// IndexColumns returns the names of the table columns the index refers to,
+// in its key and INCLUDE columns, its expressions and its predicate. Column
+// references in expressions are found by a naive scan for identifiers, so the
+// result may also contain function names and keywords.
+func IndexColumns(ind *catalog.Index) []string {
+	var cols []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			cols = append(cols, name)
+		}
+	}
+	for i, attr := range ind.Attrs() {
+		if i < len(ind.Keys()) && ind.Keys()[i] != 0 {
+			add(attr)
+			continue
+		}
+		for _, id := range identifiers(attr) {
+			add(id)
+		}
+	}
+	for _, id := range identifiers(ind.Pred()) {
+		add(id)
+	}
+	return cols
+}
+
+// Returns the identifiers in a SQL expression, in order of appearance, skipping
+// string literals. Quoted identifiers are unquoted.
+// E.g. "lower((email)::text) = 'x'" -> ["lower", "email", "text"]
+func identifiers(expr string) []string {
+	var (
+		ids []string
+		cur strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			ids = append(ids, cur.String())
+			cur.Reset()
+		}
+	}
+	rs := []rune(expr)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch {
+		case c == '\'':
+			flush()
+			for i++; i < len(rs) && rs[i] != '\''; i++ {
+			}
+		case c == '"':
+			flush()
+			for i++; i < len(rs) && rs[i] != '"'; i++ {
+				cur.WriteRune(rs[i])
+			}
+			flush()
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+			cur.Len() > 0 && (c >= '0' && c <= '9' || c == '$'):
+			cur.WriteRune(c)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return ids
+}
@@ -0,0 +1,46 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHotBlockers(t *testing.T) {
+	// A nil UpdatedColumns means DefaultUpdatedColumns.
+	opts := HotOptions{MinHotRatio: 0.5, MinUpdates: 1000}
+	blockers, err := HotBlockers(context.Background(), loadFixture(t, "indexes.json"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// users has a HOT ratio of 75%; orders 20%.
+	if len(blockers) != 1 || blockers[0].Table.Name() != "orders" {
+		t.Fatalf("got %d blockers, want orders only", len(blockers))
+	}
+	// The invalid status index is still maintained, and the partial index's
+	// predicate refers to deleted_at.
+	want := []string{"orders_status_idx", "orders_user_id_created_at_idx"}
+	if got := indexNames(blockers[0].Indexes); !equalStrings(got, want) {
+		t.Errorf("got blocking indexes %v, want %v", got, want)
+	}
+	if got, want := blockers[0].Columns, []string{"deleted_at", "status"}; !equalStrings(got, want) {
+		t.Errorf("got columns %v, want %v", got, want)
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"", nil},
+		{"lower((email)::text)", []string{"lower", "email", "text"}},
+		{"(deleted_at IS NULL)", []string{"deleted_at", "IS", "NULL"}},
+		{`(("Status")::text <> 'status, done'::text)`, []string{"Status", "text", "text"}},
+		{"(x2 + 10)", []string{"x2"}},
+	}
+	for _, tt := range tests {
+		if got := identifiers(tt.expr); !equalStrings(got, tt.want) {
+			t.Errorf("identifiers(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package check
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// UnvalidatedConstraints returns the constraints that were added NOT VALID
+// and never validated: they apply to new rows only, so existing rows may
+// violate them.
+func UnvalidatedConstraints(ctx context.Context, src catalog.Source) ([]*catalog.Constraint, error) {
+	constraints, err := src.Constraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*catalog.Constraint
+	for _, c := range constraints {
+		if !c.IsValidated() {
+			answer = append(answer, c)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].QualifiedTableName() != answer[j].QualifiedTableName() {
+			return answer[i].QualifiedTableName() < answer[j].QualifiedTableName()
+		}
+		return answer[i].Name() < answer[j].Name()
+	})
+	return answer, nil
+}
+
+// DisabledTriggers returns the triggers that never fire, typically because
+// they were disabled for a bulk load and never re-enabled. Disabled internal
+// triggers mean that a foreign key is not being enforced.
+func DisabledTriggers(ctx context.Context, src catalog.Source) ([]*catalog.Trigger, error) {
+	triggers, err := src.Triggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*catalog.Trigger
+	for _, tg := range triggers {
+		if tg.IsDisabled() {
+			answer = append(answer, tg)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].QualifiedTableName() != answer[j].QualifiedTableName() {
+			return answer[i].QualifiedTableName() < answer[j].QualifiedTableName()
+		}
+		return answer[i].Name() < answer[j].Name()
+	})
+	return answer, nil
+}
@@ -0,0 +1,34 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUnvalidatedConstraints(t *testing.T) {
+	constraints, err := UnvalidatedConstraints(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range constraints {
+		got = append(got, c.Name())
+	}
+	if want := []string{"orders_total_check", "orders_user_id_fkey"}; !equalStrings(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDisabledTriggers(t *testing.T) {
+	triggers, err := DisabledTriggers(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tg := range triggers {
+		got = append(got, tg.Name())
+	}
+	if want := []string{"RI_ConstraintTrigger_c_3001", "orders_audit"}; !equalStrings(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
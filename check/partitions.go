@@ -0,0 +1,82 @@
+package check
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// A PartitionGap is a partition that lacks an index that one of its sibling
+// partitions has, typically because the index was created on individual
+// partitions rather than on the partitioned table.
+type PartitionGap struct {
+	Partition *catalog.Table
+	Index     *catalog.Index // the sibling's index
+}
+
+// PartitionIndexGaps returns, for each partition, the indexes that some other
+// partition of the same table has but it does not. Indexes are compared by
+// their attributes, uniqueness and predicate, since the partitions are
+// different tables. Queries that rely on such an index are fast on some
+// partitions and slow on others. The result is sorted by partition name.
+func PartitionIndexGaps(ctx context.Context, src catalog.Source) ([]PartitionGap, error) {
+	tables, err := src.AllTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := src.AllIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexesByTable := make(map[pgtype.OID][]*catalog.Index)
+	for _, ind := range indexes {
+		indexesByTable[ind.TableOID()] = append(indexesByTable[ind.TableOID()], ind)
+	}
+	partitionsByParent := make(map[pgtype.OID][]*catalog.Table)
+	for _, t := range tables {
+		if t.IsPartition() {
+			partitionsByParent[t.ParentOID()] = append(partitionsByParent[t.ParentOID()], t)
+		}
+	}
+	var answer []PartitionGap
+	for _, partitions := range partitionsByParent {
+		// Collect the distinct indexes of all the siblings, in order.
+		var (
+			keys    []string
+			example = make(map[string]*catalog.Index)
+			has     = make(map[pgtype.OID]map[string]bool)
+		)
+		for _, t := range partitions {
+			has[t.OID()] = make(map[string]bool)
+			for _, ind := range indexesByTable[t.OID()] {
+				key := partitionIndexKey(ind)
+				has[t.OID()][key] = true
+				if example[key] == nil {
+					example[key] = ind
+					keys = append(keys, key)
+				}
+			}
+		}
+		for _, t := range partitions {
+			for _, key := range keys {
+				if !has[t.OID()][key] {
+					answer = append(answer, PartitionGap{Partition: t, Index: example[key]})
+				}
+			}
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Partition.QualifiedName() < answer[j].Partition.QualifiedName()
+	})
+	return answer, nil
+}
+
+// Identifies an index independently of its table, such that equivalent
+// indexes on sibling partitions have the same key.
+func partitionIndexKey(ind *catalog.Index) string {
+	return strconv.FormatBool(ind.IsUnique()) + "\x00" + ind.Pred() + "\x00" + strings.Join(ind.Attrs(), "\x00")
+}
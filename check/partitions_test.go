@@ -0,0 +1,74 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPartitionedDuplicatesAndUnused(t *testing.T) {
+	ctx := context.Background()
+	src := loadFixture(t, "partitions.json")
+
+	// The partitions' indexes are duplicates too, but only the partitioned
+	// indexes should be reported.
+	sets, err := DuplicateIndexSets(ctx, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("got %d duplicate sets, want 1", len(sets))
+	}
+	want := []string{"events_created_at_idx", "events_created_at_idx1"}
+	if got := indexNames(sets[0]); !equalStrings(got, want) {
+		t.Errorf("got duplicates %v, want %v", got, want)
+	}
+
+	// Each of events_created_at_idx's partition indexes has been scanned at
+	// most 10 times, but together they have been scanned 11 times.
+	unused, err := UnusedIndexes(ctx, src, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexNames(unused), []string{"events_created_at_idx1"}; !equalStrings(got, want) {
+		t.Fatalf("got unused %v, want %v", got, want)
+	}
+	if ind := unused[0]; ind.NumPartitions() != 2 || ind.Size() != 2<<20 || ind.NumRows() != 110000 {
+		t.Errorf("got %d partitions, %d bytes, %d rows; want 2, %d, 110000",
+			ind.NumPartitions(), ind.Size(), ind.NumRows(), 2<<20)
+	}
+}
+
+func TestPartitionIndexGaps(t *testing.T) {
+	gaps, err := PartitionIndexGaps(context.Background(), loadFixture(t, "partitions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range gaps {
+		got = append(got, g.Partition.Name()+": "+g.Index.Name())
+	}
+	want := []string{"events_2025: events_2026_user_id_idx"}
+	if !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPartitionedTableRollups(t *testing.T) {
+	rollups, err := TableRollups(context.Background(), loadFixture(t, "partitions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The partitions are folded into events, along with their indexes: the two
+	// partitioned indexes, and events_2026_user_id_idx, which has no parent.
+	if len(rollups) != 1 {
+		t.Fatalf("got %d rollups, want 1", len(rollups))
+	}
+	r := rollups[0]
+	if r.Table.Name() != "events" || r.NumIndexes != 3 || r.IndexSize != 5<<20 || r.Table.HeapSize() != 18<<20 {
+		t.Errorf("got %s with %d indexes of %d bytes and a heap of %d bytes; want events with 3 of %d and %d",
+			r.Table.Name(), r.NumIndexes, r.IndexSize, r.Table.HeapSize(), 5<<20, 18<<20)
+	}
+	if r.Table.NumInserts() != 110000 {
+		t.Errorf("got %d inserts, want 110000", r.Table.NumInserts())
+	}
+}
@@ -0,0 +1,71 @@
+package check
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// ReplicationOptions determine which slots and replicas are reported.
+type ReplicationOptions struct {
+	MaxRetainedWAL    catalog.Bytes // slots retaining more WAL are reported
+	MaxCatalogXminAge int           // logical slots with an older catalog_xmin are reported
+	MaxLagBytes       catalog.Bytes // replicas further behind are reported
+	MaxLagTime        time.Duration // replicas with a longer replay lag are reported
+}
+
+// A SlotProblem is a replication slot that is, or may soon become, a burden
+// on the server.
+type SlotProblem struct {
+	Slot     *catalog.ReplicationSlot
+	Problems []string // "inactive", "retains WAL", "holds back vacuum"
+}
+
+// ReplicationSlotProblems returns the slots that no consumer is connected to,
+// that retain more than opts.MaxRetainedWAL of WAL, or, for logical slots,
+// whose catalog_xmin is older than opts.MaxCatalogXminAge transactions, which
+// stops vacuum from removing dead rows in the system catalogs. It takes the
+// slots rather than a source because the report also lists every slot, and
+// the slots are not cached. The result is sorted by decreasing retained WAL.
+func ReplicationSlotProblems(slots []*catalog.ReplicationSlot, opts ReplicationOptions) []SlotProblem {
+	var answer []SlotProblem
+	for _, s := range slots {
+		var problems []string
+		if !s.IsActive() {
+			problems = append(problems, "inactive")
+		}
+		if s.RetainedWAL() > opts.MaxRetainedWAL {
+			problems = append(problems, "retains WAL")
+		}
+		if s.IsLogical() && s.CatalogXminAge() > opts.MaxCatalogXminAge {
+			problems = append(problems, "holds back vacuum")
+		}
+		if len(problems) != 0 {
+			answer = append(answer, SlotProblem{Slot: s, Problems: problems})
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Slot.RetainedWAL() > answer[j].Slot.RetainedWAL()
+	})
+	return answer
+}
+
+// LaggingReplicas returns the replicas that have yet to replay more than
+// opts.MaxLagBytes of WAL, or whose replay lag exceeds opts.MaxLagTime, sorted
+// by decreasing lag in bytes.
+func LaggingReplicas(ctx context.Context, src catalog.ReplicationSource, opts ReplicationOptions) ([]*catalog.Replica, error) {
+	replicas, err := src.Replicas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var answer []*catalog.Replica
+	for _, r := range replicas {
+		if r.LagBytes() > opts.MaxLagBytes || r.ReplayLag() > opts.MaxLagTime {
+			answer = append(answer, r)
+		}
+	}
+	sort.SliceStable(answer, func(i, j int) bool { return answer[i].LagBytes() > answer[j].LagBytes() })
+	return answer, nil
+}
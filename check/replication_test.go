@@ -0,0 +1,43 @@
+package check
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+var testReplicationOptions = ReplicationOptions{
+	MaxRetainedWAL:    1 * catalog.GiB,
+	MaxCatalogXminAge: 10000000,
+	MaxLagBytes:       256 * catalog.MiB,
+	MaxLagTime:        time.Minute,
+}
+
+func TestReplicationSlotProblems(t *testing.T) {
+	slots, err := loadFixture(t, "indexes.json").ReplicationSlots(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := ReplicationSlotProblems(slots, testReplicationOptions)
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Slot.Name()+": "+strings.Join(p.Problems, ", "))
+	}
+	want := []string{"standby2: inactive, retains WAL", "search_sync: holds back vacuum"}
+	if !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLaggingReplicas(t *testing.T) {
+	replicas, err := LaggingReplicas(context.Background(), loadFixture(t, "indexes.json"), testReplicationOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replicas) != 1 || replicas[0].ApplicationName() != "reporting" {
+		t.Errorf("got %v, want [reporting]", replicas)
+	}
+}
@@ -0,0 +1,118 @@
+package check
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// DefaultTenantColumns matches the names of columns that identify the tenant
+// to which a row belongs.
+const DefaultTenantColumns = `^(tenant|org|organization)_id$`
+
+// RLSOptions determine which tables RowSecurityGaps checks. A table is checked
+// if it is named in Tables or has a column matching TenantColumns. Partitioned
+// tables are checked like any other, since queries through them are subject
+// to their own policies, not their partitions'.
+type RLSOptions struct {
+	Tables        map[string]bool // names of tables, qualified unless in "public"
+	TenantColumns *regexp.Regexp  // names of tenant columns; nil to match none
+}
+
+// An RLSGap is a table whose rows are not fully protected by row-level
+// security.
+type RLSGap struct {
+	Table    *catalog.Table
+	Problems []string // "RLS disabled", "not forced", "no SELECT policy", etc.
+}
+
+// The commands for which a tenant table needs a policy.
+var rlsCommands = []string{"SELECT", "INSERT", "UPDATE", "DELETE"}
+
+// RowSecurityGaps checks the tables selected by opts and returns those on
+// which row-level security is disabled, is not forced for the table's owner,
+// or lacks a permissive policy for some command. Restrictive policies only
+// narrow the access that permissive ones grant, so they do not count. The
+// result is in the order of src.AllTables. A name in opts.Tables that matches
+// no table is ignored; q.v. MissingTables.
+func RowSecurityGaps(ctx context.Context, src catalog.Source, opts RLSOptions) ([]RLSGap, error) {
+	tables, err := src.AllTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := src.Columns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := src.Policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantTables := make(map[pgtype.OID]bool)
+	if opts.TenantColumns != nil {
+		for _, c := range columns {
+			if opts.TenantColumns.MatchString(c.Name()) {
+				tenantTables[c.TableOID()] = true
+			}
+		}
+	}
+	byTable := make(map[string][]*catalog.Policy)
+	for _, p := range policies {
+		key := p.Namespace() + "." + p.TableName()
+		byTable[key] = append(byTable[key], p)
+	}
+	var answer []RLSGap
+	for _, t := range tables {
+		if !opts.Tables[t.QualifiedName()] && !tenantTables[t.OID()] {
+			continue
+		}
+		var problems []string
+		if !t.RowSecurity() {
+			problems = append(problems, "RLS disabled")
+		} else if !t.ForceRowSecurity() {
+			problems = append(problems, "not forced")
+		}
+		for _, cmd := range rlsCommands {
+			if !hasPermissivePolicy(byTable[t.Namespace()+"."+t.Name()], cmd) {
+				problems = append(problems, "no "+cmd+" policy")
+			}
+		}
+		if len(problems) != 0 {
+			answer = append(answer, RLSGap{Table: t, Problems: problems})
+		}
+	}
+	return answer, nil
+}
+
+// MissingTables returns the names in opts.Tables, in alphabetical order, that
+// are not the qualified name of any of the tables. They should be reported,
+// lest a typo leave a tenant table unchecked; but they are not an error, since
+// the same names are used to check every database of a cluster, not all of
+// which have every table.
+func MissingTables(tables []*catalog.Table, opts RLSOptions) []string {
+	found := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		found[t.QualifiedName()] = true
+	}
+	var missing []string
+	for name := range opts.Tables {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Reports whether any of the policies is permissive and applies to cmd.
+func hasPermissivePolicy(policies []*catalog.Policy, cmd string) bool {
+	for _, p := range policies {
+		if p.IsPermissive() && p.Covers(cmd) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package check
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRowSecurityGaps(t *testing.T) {
+	src := loadFixture(t, "indexes.json")
+	for _, tc := range []struct {
+		opts RLSOptions
+		want []string
+	}{
+		{RLSOptions{}, nil},
+		{RLSOptions{TenantColumns: regexp.MustCompile(DefaultTenantColumns)}, nil},
+		{
+			// users only has permissive SELECT and UPDATE policies.
+			RLSOptions{Tables: map[string]bool{"users": true}},
+			[]string{"users: not forced, no INSERT policy, no DELETE policy"},
+		},
+		{
+			RLSOptions{Tables: map[string]bool{"users": true}, TenantColumns: regexp.MustCompile(`^user_id$`)},
+			[]string{
+				"users: not forced, no INSERT policy, no DELETE policy",
+				"orders: RLS disabled, no SELECT policy, no INSERT policy, no UPDATE policy, no DELETE policy",
+			},
+		},
+	} {
+		gaps, err := RowSecurityGaps(context.Background(), src, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, g := range gaps {
+			got = append(got, g.Table.Name()+": "+strings.Join(g.Problems, ", "))
+		}
+		if !equalStrings(got, tc.want) {
+			t.Errorf("%+v: got %q, want %q", tc.opts, got, tc.want)
+		}
+	}
+}
+
+func TestRowSecurityGapsPartitioned(t *testing.T) {
+	// The partitioned table has no row in pg_stat_user_tables, but is checked.
+	opts := RLSOptions{Tables: map[string]bool{"events": true}}
+	gaps, err := RowSecurityGaps(context.Background(), loadFixture(t, "partitions.json"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 1 || gaps[0].Table.Name() != "events" || !gaps[0].Table.IsPartitioned() {
+		t.Errorf("got %+v, want a gap for the partitioned table events", gaps)
+	}
+}
+
+func TestRowSecurityGapsUnknownTable(t *testing.T) {
+	src := loadFixture(t, "indexes.json")
+	opts := RLSOptions{Tables: map[string]bool{"users": true, "uesrs": true, "audit.log": true}}
+	gaps, err := RowSecurityGaps(context.Background(), src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 1 || gaps[0].Table.Name() != "users" {
+		t.Errorf("got %d gaps, want one on users", len(gaps))
+	}
+	if got, want := MissingTables(src.Tables, opts), []string{"audit.log", "uesrs"}; !equalStrings(got, want) {
+		t.Errorf("MissingTables: got %q, want %q", got, want)
+	}
+}
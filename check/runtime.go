@@ -0,0 +1,113 @@
+package check
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// RuntimeOptions determine which sessions and prepared transactions
+// CheckRuntime reports.
+type RuntimeOptions struct {
+	MaxXactAge     time.Duration // transactions open longer are reported
+	MaxIdleInXact  time.Duration // sessions idle in transaction longer are reported
+	MaxPreparedAge time.Duration // prepared transactions older are reported
+}
+
+// RuntimeHealth summarizes the problems in a server's live activity.
+type RuntimeHealth struct {
+	LongTransactions  []*catalog.Session      // sorted by decreasing transaction age
+	IdleInTransaction []*catalog.Session      // sorted by decreasing time idle
+	LockChains        []LockChain             // sorted by decreasing number of waiters
+	OrphanedPrepared  []*catalog.PreparedXact // sorted by decreasing age
+}
+
+// A LockChain is a session holding locks that other sessions are waiting
+// for, directly or indirectly, while not itself waiting for any session.
+type LockChain struct {
+	Blocker *catalog.Session
+	Waiters []*catalog.Session // in breadth-first order
+}
+
+// Empty reports whether no problems were found.
+func (h *RuntimeHealth) Empty() bool {
+	return len(h.LongTransactions) == 0 && len(h.IdleInTransaction) == 0 &&
+		len(h.LockChains) == 0 && len(h.OrphanedPrepared) == 0
+}
+
+// CheckRuntime inspects the sessions and prepared transactions of src. Long
+// transactions and idle sessions hold back vacuum's cleanup horizon and may
+// hold locks; prepared transactions that are never committed or rolled back
+// do the same, and survive a restart.
+func CheckRuntime(ctx context.Context, src catalog.RuntimeSource, opts RuntimeOptions) (*RuntimeHealth, error) {
+	sessions, err := src.Sessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := src.PreparedXacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var h RuntimeHealth
+	for _, s := range sessions {
+		if s.XactAge() > opts.MaxXactAge {
+			h.LongTransactions = append(h.LongTransactions, s)
+		}
+		if s.IsIdleInTransaction() && s.StateAge() > opts.MaxIdleInXact {
+			h.IdleInTransaction = append(h.IdleInTransaction, s)
+		}
+	}
+	sort.SliceStable(h.LongTransactions, func(i, j int) bool {
+		return h.LongTransactions[i].XactAge() > h.LongTransactions[j].XactAge()
+	})
+	sort.SliceStable(h.IdleInTransaction, func(i, j int) bool {
+		return h.IdleInTransaction[i].StateAge() > h.IdleInTransaction[j].StateAge()
+	})
+	h.LockChains = lockChains(sessions)
+	for _, p := range prepared {
+		if p.Age() > opts.MaxPreparedAge {
+			h.OrphanedPrepared = append(h.OrphanedPrepared, p)
+		}
+	}
+	sort.SliceStable(h.OrphanedPrepared, func(i, j int) bool {
+		return h.OrphanedPrepared[i].Age() > h.OrphanedPrepared[j].Age()
+	})
+	return &h, nil
+}
+
+// Builds the lock chains rooted at each session that blocks another but is
+// not blocked itself. Sessions blocked by one that is not in sessions, such
+// as a prepared transaction, are not reported.
+func lockChains(sessions []*catalog.Session) []LockChain {
+	waiters := make(map[int][]*catalog.Session) // blocker PID -> sessions it blocks
+	for _, s := range sessions {
+		for _, pid := range s.BlockedBy() {
+			waiters[int(pid)] = append(waiters[int(pid)], s)
+		}
+	}
+	var chains []LockChain
+	for _, s := range sessions {
+		if len(s.BlockedBy()) != 0 || len(waiters[s.PID()]) == 0 {
+			continue
+		}
+		chain := LockChain{Blocker: s}
+		seen := map[int]bool{s.PID(): true}
+		queue := []*catalog.Session{s}
+		for len(queue) != 0 {
+			blocker := queue[0]
+			queue = queue[1:]
+			for _, w := range waiters[blocker.PID()] {
+				if !seen[w.PID()] {
+					seen[w.PID()] = true
+					chain.Waiters = append(chain.Waiters, w)
+					queue = append(queue, w)
+				}
+			}
+		}
+		chains = append(chains, chain)
+	}
+	sort.SliceStable(chains, func(i, j int) bool { return len(chains[i].Waiters) > len(chains[j].Waiters) })
+	return chains
+}
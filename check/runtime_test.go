@@ -0,0 +1,62 @@
+package check
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckRuntime(t *testing.T) {
+	ctx := context.Background()
+	opts := RuntimeOptions{MaxXactAge: 5 * time.Minute, MaxIdleInXact: time.Minute, MaxPreparedAge: 5 * time.Minute}
+	h, err := CheckRuntime(ctx, loadFixture(t, "indexes.json"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pids := func(h *RuntimeHealth) (long, idle []int) {
+		for _, s := range h.LongTransactions {
+			long = append(long, s.PID())
+		}
+		for _, s := range h.IdleInTransaction {
+			idle = append(idle, s.PID())
+		}
+		return
+	}
+	long, idle := pids(h)
+	if want := []int{6004, 6001}; !reflect.DeepEqual(long, want) {
+		t.Errorf("long transactions: got %v, want %v", long, want)
+	}
+	if want := []int{6001}; !reflect.DeepEqual(idle, want) {
+		t.Errorf("idle in transaction: got %v, want %v", idle, want)
+	}
+	if len(h.LockChains) != 1 {
+		t.Fatalf("got %d lock chains, want 1", len(h.LockChains))
+	}
+	chain := h.LockChains[0]
+	var waiters []int
+	for _, s := range chain.Waiters {
+		waiters = append(waiters, s.PID())
+	}
+	if chain.Blocker.PID() != 6001 || !reflect.DeepEqual(waiters, []int{6002, 6003}) {
+		t.Errorf("got chain %d <- %v, want 6001 <- [6002 6003]", chain.Blocker.PID(), waiters)
+	}
+	if len(h.OrphanedPrepared) != 1 || h.OrphanedPrepared[0].GID() != "payment-17" {
+		t.Errorf("got orphaned prepared transactions %v, want [payment-17]", h.OrphanedPrepared)
+	}
+	if h.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+
+	// Nothing is old enough with generous thresholds, but the chain remains.
+	opts = RuntimeOptions{MaxXactAge: 3 * time.Hour, MaxIdleInXact: time.Hour, MaxPreparedAge: 7 * 24 * time.Hour}
+	if h, err = CheckRuntime(ctx, loadFixture(t, "indexes.json"), opts); err != nil {
+		t.Fatal(err)
+	}
+	if long, idle = pids(h); len(long) != 0 || len(idle) != 0 || len(h.OrphanedPrepared) != 0 {
+		t.Errorf("got %v, %v, %v; want none", long, idle, h.OrphanedPrepared)
+	}
+	if len(h.LockChains) != 1 {
+		t.Errorf("got %d lock chains, want 1", len(h.LockChains))
+	}
+}
@@ -0,0 +1,93 @@
+package check
+
+import (
+	"context"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// A SecurityFinding is a role, grant or function that weakens the database's
+// security. Exactly one of Role, Grant and Function is set, according to Kind.
+type SecurityFinding struct {
+	Kind     string // see SecurityAudit
+	Role     *catalog.Role
+	Grant    *catalog.Grant
+	Function *catalog.Function
+}
+
+// SecurityAudit reports, in this order:
+//
+//   - "superuser": roles that bypass all permission checks, other than the
+//     bootstrap superuser;
+//   - "createrole": roles that can create other roles, and grant them
+//     membership in any role, which is nearly as dangerous;
+//   - "bypassrls": roles that bypass row-level security;
+//   - "no expiry": login roles whose password never expires;
+//   - "md5": login roles whose password is stored as an md5 hash, or in plain
+//     text, rather than as a SCRAM verifier;
+//   - "public create": schemas in which every role may create objects, which
+//     lets any role plant a function or operator that others call by mistake;
+//   - "public grant": tables, views and sequences on which PUBLIC has
+//     privileges;
+//   - "security definer": SECURITY DEFINER functions that do not pin their
+//     search_path, which lets the caller substitute the objects they use.
+//
+// The attributes of superusers are not reported separately, since a
+// superuser has every privilege anyway.
+func SecurityAudit(ctx context.Context, src catalog.Source) ([]SecurityFinding, error) {
+	roles, err := src.Roles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := src.PublicGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	functions, err := src.Functions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var answer []SecurityFinding
+	addRoles := func(kind string, match func(r *catalog.Role) bool) {
+		for _, r := range roles {
+			if match(r) {
+				answer = append(answer, SecurityFinding{Kind: kind, Role: r})
+			}
+		}
+	}
+	addRoles("superuser", func(r *catalog.Role) bool { return r.IsSuperuser() && !r.IsBootstrap() })
+	addRoles("createrole", func(r *catalog.Role) bool { return r.CanCreateRole() && !r.IsSuperuser() })
+	addRoles("bypassrls", func(r *catalog.Role) bool { return r.BypassesRLS() && !r.IsSuperuser() })
+	addRoles("no expiry", func(r *catalog.Role) bool {
+		return r.CanLogin() && r.NeverExpires() && r.PasswordType() != "none"
+	})
+	addRoles("md5", func(r *catalog.Role) bool {
+		return r.CanLogin() && (r.PasswordType() == "md5" || r.PasswordType() == "plain")
+	})
+	for _, g := range grants {
+		if g.ObjectType() == "schema" && hasPrivilege(g, "CREATE") {
+			answer = append(answer, SecurityFinding{Kind: "public create", Grant: g})
+		}
+	}
+	for _, g := range grants {
+		if g.ObjectType() == "table" {
+			answer = append(answer, SecurityFinding{Kind: "public grant", Grant: g})
+		}
+	}
+	for _, f := range functions {
+		if f.IsSecurityDefiner() && !f.PinsSearchPath() {
+			answer = append(answer, SecurityFinding{Kind: "security definer", Function: f})
+		}
+	}
+	return answer, nil
+}
+
+// Reports whether g includes the named privilege.
+func hasPrivilege(g *catalog.Grant, privilege string) bool {
+	for _, p := range g.Privileges() {
+		if p == privilege {
+			return true
+		}
+	}
+	return false
+}
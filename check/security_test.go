@@ -0,0 +1,40 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSecurityAudit(t *testing.T) {
+	findings, err := SecurityAudit(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		var name string
+		switch {
+		case f.Role != nil:
+			name = f.Role.Name()
+		case f.Grant != nil:
+			name = f.Grant.Name()
+		case f.Function != nil:
+			name = f.Function.Name()
+		}
+		got = append(got, f.Kind+": "+name)
+	}
+	want := []string{
+		"superuser: admin",
+		"createrole: ops",
+		"bypassrls: ops",
+		"no expiry: admin",
+		"no expiry: ops",
+		"md5: app",
+		"public create: public",
+		"public grant: users",
+		"security definer: touch_user",
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
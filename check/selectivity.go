@@ -0,0 +1,69 @@
+package check
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// SelectivityOptions determine which indexes LowSelectivityIndexes reports.
+type SelectivityOptions struct {
+	MaxDistinct int // max. distinct values in the leading column
+	MinRows     int // indexes with fewer rows are ignored
+}
+
+// A LowSelectivityIndex is a non-unique index whose leading column has so few
+// distinct values that an index scan seldom beats a sequential scan, except
+// when searching for a rare value.
+type LowSelectivityIndex struct {
+	Index    *catalog.Index
+	Stats    *catalog.ColumnStats // of the leading column
+	Distinct float64              // estimated distinct values in the leading column
+}
+
+// Column returns the name of the index's leading column.
+func (l LowSelectivityIndex) Column() string { return l.Stats.Column() }
+
+// Skewed reports whether the leading column's most common value accounts for
+// at least half of the rows, in which case a partial index that excludes that
+// value would be much smaller, and just as useful for finding the rare values.
+func (l LowSelectivityIndex) Skewed() bool {
+	freqs := l.Stats.MostCommonFreqs()
+	return len(freqs) > 0 && len(l.Stats.MostCommonVals()) > 0 && freqs[0] >= 0.5
+}
+
+// LowSelectivityIndexes returns the valid, non-unique, non-partial indexes
+// whose leading column has at most opts.MaxDistinct distinct values according
+// to the planner's statistics, sorted by decreasing size. Indexes that lead
+// with an expression are skipped, as are columns that have not been analyzed.
+// Partition indexes are rolled up into their partitioned indexes first.
+func LowSelectivityIndexes(ctx context.Context, src catalog.Source, opts SelectivityOptions) ([]LowSelectivityIndex, error) {
+	indexes, err := logicalIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := src.ColumnStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statsByName := catalog.ColumnStatsByName(stats)
+	var answer []LowSelectivityIndex
+	for _, ind := range indexes {
+		if ind.IsUnique() || ind.Pred() != "" || ind.NumRows() < opts.MinRows {
+			continue
+		}
+		if len(ind.Keys()) == 0 || ind.Keys()[0] == 0 {
+			continue // leads with an expression
+		}
+		s := statsByName[catalog.ColumnStatsKey{Table: ind.TableName(), Column: ind.Attrs()[0]}]
+		if s == nil {
+			continue
+		}
+		if n := s.DistinctValues(ind.NumRows()); n <= float64(opts.MaxDistinct) {
+			answer = append(answer, LowSelectivityIndex{Index: ind, Stats: s, Distinct: n})
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool { return answer[i].Index.Size() > answer[j].Index.Size() })
+	return answer, nil
+}
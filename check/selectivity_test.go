@@ -0,0 +1,50 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+func TestLowSelectivityIndexes(t *testing.T) {
+	src := loadFixture(t, "indexes.json")
+	tests := []struct {
+		opts SelectivityOptions
+		want []string
+	}{
+		{SelectivityOptions{MaxDistinct: 10, MinRows: 1000}, []string{"users_active_idx"}},
+		{SelectivityOptions{MaxDistinct: 10, MinRows: 10000}, nil},
+		{SelectivityOptions{MaxDistinct: 1}, nil},
+		// Unique and partial indexes, and those leading with an expression,
+		// are never reported.
+		{SelectivityOptions{MaxDistinct: 4000}, []string{"orders_user_id_idx", "users_active_idx"}},
+	}
+	for _, tt := range tests {
+		lows, err := LowSelectivityIndexes(context.Background(), src, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var indexes []*catalog.Index
+		for _, l := range lows {
+			indexes = append(indexes, l.Index)
+		}
+		if got := indexNames(indexes); !equalStrings(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestLowSelectivityIndexSkewed(t *testing.T) {
+	lows, err := LowSelectivityIndexes(context.Background(), loadFixture(t, "indexes.json"), SelectivityOptions{MaxDistinct: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lows) != 1 {
+		t.Fatalf("got %d indexes, want 1", len(lows))
+	}
+	if l := lows[0]; l.Column() != "active" || l.Distinct != 2 || !l.Skewed() {
+		t.Errorf("got column %s, %v distinct values, skewed %t; want active, 2, true",
+			l.Column(), l.Distinct, l.Skewed())
+	}
+}
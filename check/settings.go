@@ -0,0 +1,133 @@
+package check
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// SettingsOptions describe the machine that the server runs on, against which
+// some settings are judged.
+type SettingsOptions struct {
+	SSD    bool          // if true, storage is solid-state
+	Memory catalog.Bytes // the machine's RAM; zero if unknown
+}
+
+// A SettingFinding is a configuration parameter with a risky or suboptimal
+// value.
+type SettingFinding struct {
+	Setting   *catalog.Setting
+	Advice    string
+	Suggested string // a better value, in the form SHOW displays it
+}
+
+// A settingRule reports whether a parameter's value is risky or suboptimal,
+// and suggests a better one.
+type settingRule struct {
+	name    string // of the parameter
+	advice  string
+	suggest func(s *catalog.Setting, opts SettingsOptions) (value string, bad bool)
+}
+
+var settingRules = []settingRule{
+	{
+		name:   "autovacuum",
+		advice: "dead rows pile up and transaction IDs are not frozen, eventually forcing a shutdown to prevent wraparound",
+		suggest: func(s *catalog.Setting, _ SettingsOptions) (string, bool) {
+			return "on", !s.IsOn()
+		},
+	},
+	{
+		name:   "track_counts",
+		advice: "autovacuum cannot tell which tables need vacuuming, and index usage statistics, hence the unused index report, are meaningless",
+		suggest: func(s *catalog.Setting, _ SettingsOptions) (string, bool) {
+			return "on", !s.IsOn()
+		},
+	},
+	{
+		name:   "fsync",
+		advice: "a crash or power failure can corrupt the database beyond repair",
+		suggest: func(s *catalog.Setting, _ SettingsOptions) (string, bool) {
+			return "on", !s.IsOn()
+		},
+	},
+	{
+		name:   "full_page_writes",
+		advice: "a crash during a partial page write can corrupt data unrecoverably",
+		suggest: func(s *catalog.Setting, _ SettingsOptions) (string, bool) {
+			return "on", !s.IsOn()
+		},
+	},
+	{
+		name:   "shared_buffers",
+		advice: "too small to cache the working set; about a quarter of the machine's memory is a common starting point",
+		suggest: func(s *catalog.Setting, opts SettingsOptions) (string, bool) {
+			if opts.Memory == 0 {
+				// Without the machine's size, only flag the default or less.
+				return "1GB", s.Bytes() <= 128*catalog.MiB
+			}
+			return memorySetting(opts.Memory / 4), s.Bytes() < opts.Memory/10
+		},
+	},
+	{
+		name:   "maintenance_work_mem",
+		advice: "too small for vacuum and index builds to run efficiently on large tables",
+		suggest: func(s *catalog.Setting, opts SettingsOptions) (string, bool) {
+			if opts.Memory == 0 {
+				return "256MB", s.Bytes() <= 64*catalog.MiB // the default
+			}
+			want := catalog.Bytes(1 * catalog.GiB)
+			if opts.Memory/16 < want {
+				want = opts.Memory / 16
+			}
+			return memorySetting(want), s.Bytes() < want/4
+		},
+	},
+	{
+		name:   "random_page_cost",
+		advice: "the default assumes spinning disks; on SSDs it makes the planner shun index scans",
+		suggest: func(s *catalog.Setting, opts SettingsOptions) (string, bool) {
+			return "1.1", opts.SSD && s.Float() >= 4
+		},
+	},
+	{
+		name:   "log_min_duration_statement",
+		advice: "slow queries are not logged, so there is no record of what to optimize",
+		suggest: func(s *catalog.Setting, _ SettingsOptions) (string, bool) {
+			return "1s", s.Float() < 0
+		},
+	},
+}
+
+// Formats an amount of memory as a setting value, in whole megabytes or
+// gigabytes.
+func memorySetting(b catalog.Bytes) string {
+	if b >= catalog.GiB && b%catalog.GiB == 0 {
+		return strconv.FormatInt(int64(b/catalog.GiB), 10) + "GB"
+	}
+	return strconv.FormatInt(int64(b/catalog.MiB), 10) + "MB"
+}
+
+// SettingProblems returns the configuration parameters with risky or
+// suboptimal values, in order of decreasing severity: settings that put data
+// or availability at risk come first, then those that hurt performance or
+// observability. Parameters that src does not supply are skipped.
+func SettingProblems(ctx context.Context, src catalog.Source, opts SettingsOptions) ([]SettingFinding, error) {
+	settings, err := src.Settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := catalog.SettingsByName(settings)
+	var answer []SettingFinding
+	for _, rule := range settingRules {
+		s := byName[rule.name]
+		if s == nil {
+			continue
+		}
+		if value, bad := rule.suggest(s, opts); bad {
+			answer = append(answer, SettingFinding{Setting: s, Advice: rule.advice, Suggested: value})
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,34 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+func TestSettingProblems(t *testing.T) {
+	tests := []struct {
+		opts SettingsOptions
+		want []string // name=suggested
+	}{
+		{SettingsOptions{}, []string{"full_page_writes=on", "maintenance_work_mem=256MB", "log_min_duration_statement=1s"}},
+		{SettingsOptions{SSD: true}, []string{"full_page_writes=on", "maintenance_work_mem=256MB", "random_page_cost=1.1", "log_min_duration_statement=1s"}},
+		{SettingsOptions{Memory: 8 * catalog.GiB}, []string{"full_page_writes=on", "maintenance_work_mem=512MB", "log_min_duration_statement=1s"}},
+		{SettingsOptions{Memory: 64 * catalog.GiB}, []string{"full_page_writes=on", "shared_buffers=16GB", "maintenance_work_mem=1GB", "log_min_duration_statement=1s"}},
+	}
+	src := loadFixture(t, "indexes.json")
+	for _, tt := range tests {
+		findings, err := SettingProblems(context.Background(), src, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.Setting.Name()+"="+f.Suggested)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
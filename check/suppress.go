@@ -0,0 +1,115 @@
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// A Suppression records that a finding was reviewed and the index kept, so
+// that it is left out of future reports.
+type Suppression struct {
+	Check string // ID of the report section, e.g. "unused"
+	Index string // qualified name of the index; q.v. catalog.Index.QualifiedName
+}
+
+// String formats s as a line of a suppression file.
+func (s Suppression) String() string { return s.Check + " " + s.Index }
+
+// Suppressions is a set of suppressed findings.
+type Suppressions map[Suppression]bool
+
+// ReadSuppressions parses a suppression file, which has one suppression per
+// line: the ID of a report section and the qualified name of an index,
+// separated by white space. Blank lines and comments, which begin with "#",
+// are ignored.
+func ReadSuppressions(r io.Reader) (Suppressions, error) {
+	s := make(Suppressions)
+	sc := bufio.NewScanner(r)
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 2:
+			s[Suppression{Check: fields[0], Index: fields[1]}] = true
+		default:
+			return nil, fmt.Errorf("line %d: want a check and an index, got %q", lineno, sc.Text())
+		}
+	}
+	return s, sc.Err()
+}
+
+// ReadSuppressionFile reads the named suppression file; q.v. ReadSuppressions.
+// A missing file has no suppressions.
+func ReadSuppressionFile(path string) (Suppressions, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Suppressions{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadSuppressions(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Has reports whether the finding of the given check about ind is suppressed.
+func (s Suppressions) Has(check string, ind *catalog.Index) bool {
+	return s[Suppression{Check: check, Index: ind.QualifiedName()}]
+}
+
+// Indexes returns the indexes whose findings of the given check are not
+// suppressed.
+func (s Suppressions) Indexes(check string, indexes []*catalog.Index) []*catalog.Index {
+	return catalog.FilterIndexes(indexes, func(ind *catalog.Index) bool { return !s.Has(check, ind) })
+}
+
+// DuplicateSets removes the suppressed indexes from each set of duplicates,
+// under the check "duplicate", and returns the sets that still have at least
+// two indexes.
+func (s Suppressions) DuplicateSets(sets []DuplicateSet) []DuplicateSet {
+	var answer []DuplicateSet
+	for _, set := range sets {
+		if rest := s.Indexes("duplicate", set); len(rest) >= 2 {
+			answer = append(answer, rest)
+		}
+	}
+	return answer
+}
+
+// RedundantPairs returns the pairs whose redundant index is not suppressed
+// under the check "redundant".
+func (s Suppressions) RedundantPairs(pairs []RedundantPair) []RedundantPair {
+	var answer []RedundantPair
+	for _, p := range pairs {
+		if !s.Has("redundant", p.Index) {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
+
+// LowSelectivityIndexes returns the indexes that are not suppressed under the
+// check "lowselectivity".
+func (s Suppressions) LowSelectivityIndexes(indexes []LowSelectivityIndex) []LowSelectivityIndex {
+	var answer []LowSelectivityIndex
+	for _, l := range indexes {
+		if !s.Has("lowselectivity", l.Index) {
+			answer = append(answer, l)
+		}
+	}
+	return answer
+}
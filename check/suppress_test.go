@@ -0,0 +1,40 @@
+package check
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSuppressions(t *testing.T) {
+	ctx := context.Background()
+	src := loadFixture(t, "indexes.json")
+	s, err := ReadSuppressions(strings.NewReader(`
+# reviewed 2026-10-01
+duplicate users_email_idx2
+unused    orders_pkey   # needed for replication
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sets, err := DuplicateIndexSets(ctx, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.DuplicateSets(sets); len(got) != 0 {
+		t.Errorf("got %d duplicate sets, want 0", len(got))
+	}
+	unused, err := UnusedIndexes(ctx, src, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ind := range s.Indexes("unused", unused) {
+		if ind.Name() == "orders_pkey" {
+			t.Errorf("orders_pkey was not suppressed")
+		}
+	}
+
+	if _, err := ReadSuppressions(strings.NewReader("unused\n")); err == nil {
+		t.Errorf("malformed line was accepted")
+	}
+}
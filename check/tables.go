@@ -0,0 +1,93 @@
+package check
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// A TableRollup summarizes the valid, live indexes on a table.
+type TableRollup struct {
+	Table      *catalog.Table
+	NumIndexes int
+	IndexSize  catalog.Bytes // combined size of the indexes
+}
+
+// IndexRatio reports the combined size of the table's indexes relative to the
+// size of the table itself. It is zero for an empty table.
+func (r TableRollup) IndexRatio() float64 {
+	if r.Table.HeapSize() == 0 {
+		return 0
+	}
+	return float64(r.IndexSize) / float64(r.Table.HeapSize())
+}
+
+// IndexLimits are the thresholds beyond which a table is considered to be
+// over-indexed. A zero limit is ignored.
+type IndexLimits struct {
+	MaxIndexes    int     // max. number of indexes on a table
+	MaxIndexRatio float64 // max. combined index size relative to the table's
+}
+
+// TooManyIndexes reports whether r exceeds the limit on the number of indexes.
+func (lim IndexLimits) TooManyIndexes(r TableRollup) bool {
+	return lim.MaxIndexes > 0 && r.NumIndexes > lim.MaxIndexes
+}
+
+// TooLarge reports whether r exceeds the limit on the index/table size ratio.
+func (lim IndexLimits) TooLarge(r TableRollup) bool {
+	return lim.MaxIndexRatio > 0 && r.IndexRatio() > lim.MaxIndexRatio
+}
+
+// TableRollups returns a rollup of the indexes on each table, sorted by
+// decreasing index/table size ratio. Partitions and their indexes are rolled
+// up into their partitioned tables and indexes first, so that a partitioned
+// table is judged by the indexes defined on it and the size of its partitions.
+func TableRollups(ctx context.Context, src catalog.Source) ([]TableRollup, error) {
+	tables, err := src.AllTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := logicalIndexes(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	roots := catalog.PartitionRoots(tables)
+	tables = catalog.RollUpTables(tables)
+	rollups := make(map[pgtype.OID]*TableRollup, len(tables))
+	for _, t := range tables {
+		rollups[t.OID()] = &TableRollup{Table: t}
+	}
+	for _, ind := range indexes {
+		if r := rollups[rootOf(roots, ind.TableOID())]; r != nil {
+			r.NumIndexes++
+			r.IndexSize += ind.Size()
+		}
+	}
+	answer := make([]TableRollup, 0, len(tables))
+	for _, t := range tables {
+		answer = append(answer, *rollups[t.OID()])
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].IndexRatio() > answer[j].IndexRatio()
+	})
+	return answer, nil
+}
+
+// OverIndexedTables returns the rollups of the tables that exceed either of
+// the given limits.
+func OverIndexedTables(ctx context.Context, src catalog.Source, lim IndexLimits) ([]TableRollup, error) {
+	rollups, err := TableRollups(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	var answer []TableRollup
+	for _, r := range rollups {
+		if lim.TooManyIndexes(r) || lim.TooLarge(r) {
+			answer = append(answer, r)
+		}
+	}
+	return answer, nil
+}
@@ -0,0 +1,62 @@
+package check
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTableRollups(t *testing.T) {
+	rollups, err := TableRollups(context.Background(), loadFixture(t, "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollups) != 2 {
+		t.Fatalf("got %d rollups, want 2", len(rollups))
+	}
+	// Sorted by decreasing index/table size ratio; the invalid index on orders
+	// is not counted.
+	tests := []struct {
+		table      string
+		numIndexes int
+		indexSize  int64
+	}{
+		{"users", 6, 9699328},
+		{"orders", 3, 6062080},
+	}
+	for i, tt := range tests {
+		r := rollups[i]
+		if r.Table.Name() != tt.table || r.NumIndexes != tt.numIndexes || int64(r.IndexSize) != tt.indexSize {
+			t.Errorf("rollups[%d] = %s, %d indexes, %d bytes; want %s, %d, %d",
+				i, r.Table.Name(), r.NumIndexes, r.IndexSize, tt.table, tt.numIndexes, tt.indexSize)
+		}
+	}
+}
+
+func TestOverIndexedTables(t *testing.T) {
+	ctx := context.Background()
+	src := loadFixture(t, "indexes.json")
+	tests := []struct {
+		lim  IndexLimits
+		want []string
+	}{
+		{IndexLimits{}, nil},
+		{IndexLimits{MaxIndexes: 4}, []string{"users"}},
+		{IndexLimits{MaxIndexes: 2}, []string{"users", "orders"}},
+		{IndexLimits{MaxIndexRatio: 1}, []string{"users"}},
+		{IndexLimits{MaxIndexRatio: 0.1}, []string{"users", "orders"}},
+		{IndexLimits{MaxIndexes: 10, MaxIndexRatio: 3}, nil},
+	}
+	for _, tt := range tests {
+		rollups, err := OverIndexedTables(ctx, src, tt.lim)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range rollups {
+			got = append(got, r.Table.Name())
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("OverIndexedTables(%+v) = %v, want %v", tt.lim, got, tt.want)
+		}
+	}
+}
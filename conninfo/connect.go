@@ -0,0 +1,278 @@
+package conninfo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// A Host is one of the servers that the parameters name, which are tried in
+// turn until one accepts the connection.
+type Host struct {
+	Name string // host name, IP address or Unix-domain socket directory
+	Addr string // numeric address to connect to instead of looking up Name, if any
+	Port uint16
+}
+
+func (h Host) String() string {
+	if h.Addr != "" && h.Addr != h.Name {
+		return fmt.Sprintf("%s (%s), port %d", h.Name, h.Addr, h.Port)
+	}
+	return fmt.Sprintf("%s, port %d", h.Name, h.Port)
+}
+
+// Hosts returns the servers named by the host and hostaddr parameters, which
+// are comma-separated lists. The port parameter is either a single port,
+// which every server uses, or a list as long as the hosts.
+func (p *Params) Hosts() ([]Host, error) {
+	names := strings.Split(p.Get("host"), ",")
+	var addrs []string
+	if s := p.Get("hostaddr"); s != "" {
+		addrs = strings.Split(s, ",")
+		if p.Get("host") == "" || p.Source("host") == "default" {
+			names = make([]string, len(addrs)) // named by address only
+		}
+		if len(addrs) != len(names) {
+			return nil, fmt.Errorf("could not match %d host names to %d hostaddr values", len(names), len(addrs))
+		}
+	}
+	ports := strings.Split(p.Get("port"), ",")
+	if len(ports) != 1 && len(ports) != len(names) {
+		return nil, fmt.Errorf("could not match %d port numbers to %d hosts", len(ports), len(names))
+	}
+	hosts := make([]Host, len(names))
+	for i, name := range names {
+		h := &hosts[i]
+		h.Name = strings.TrimSpace(name)
+		if addrs != nil {
+			h.Addr = strings.TrimSpace(addrs[i])
+		}
+		if h.Name == "" {
+			h.Name = h.Addr
+		}
+		if h.Name == "" {
+			h.Name = "localhost"
+		}
+		port := strings.TrimSpace(ports[0])
+		if len(ports) > 1 {
+			port = strings.TrimSpace(ports[i])
+		}
+		if port == "" {
+			port = "5432"
+		}
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid port number %q (from %s)", port, p.Source("port"))
+		}
+		h.Port = uint16(n)
+	}
+	return hosts, nil
+}
+
+// ConnConfig returns the configuration with which to connect to h, and where
+// its password came from, e.g. "password file /home/u/.pgpass"; the source
+// is empty if there is no password. A password given by the parameters
+// takes precedence over the password file, which is consulted separately for
+// each host. A password file that libpq would ignore, because others can read
+// it, is ignored with a warning; q.v. Warnings.
+func (p *Params) ConnConfig(h Host) (pgx.ConnConfig, string, error) {
+	cc := pgx.ConnConfig{
+		Host:          h.Name,
+		Port:          h.Port,
+		Database:      p.Get("dbname"),
+		User:          p.Get("user"),
+		Password:      p.Get("password"),
+		RuntimeParams: make(map[string]string),
+	}
+	if h.Addr != "" {
+		cc.Host = h.Addr
+	}
+	source := p.Source("password")
+	if cc.Password == "" {
+		source = ""
+		if path := p.Get("passfile"); path != "" {
+			password, found, err := lookupPassword(path, h.Name, strconv.Itoa(int(h.Port)), cc.Database, cc.User)
+			if errors.Is(err, errPassfileIgnored) {
+				p.warn(err.Error())
+			} else if err != nil {
+				return cc, "", err
+			}
+			if found {
+				cc.Password, source = password, "password file "+path
+			}
+		}
+	}
+	if s := p.Get("connect_timeout"); s != "" {
+		n, _ := strconv.Atoi(s) // checked by Resolve
+		if n > 0 {
+			if n < 2 {
+				n = 2 // libpq's minimum
+			}
+			d := &net.Dialer{Timeout: time.Duration(n) * time.Second, KeepAlive: 5 * time.Minute}
+			cc.Dial = d.Dial
+		}
+	}
+	if s := p.Get("application_name"); s != "" {
+		cc.RuntimeParams["application_name"] = s
+	} else if s := p.Get("fallback_application_name"); s != "" {
+		cc.RuntimeParams["application_name"] = s
+	}
+	if s := p.Get("options"); s != "" {
+		cc.RuntimeParams["options"] = s
+	}
+	if err := p.configureTLS(&cc, h); err != nil {
+		return cc, "", err
+	}
+	return cc, source, nil
+}
+
+// Warnings returns the problems that ConnConfig and Connect worked around, as
+// libpq does, e.g. a password file that was ignored, each once.
+func (p *Params) Warnings() []string { return p.warnings }
+
+// Records a warning, unless it has been already.
+func (p *Params) warn(msg string) {
+	for _, w := range p.warnings {
+		if w == msg {
+			return
+		}
+	}
+	p.warnings = append(p.warnings, msg)
+}
+
+// Sets up TLS for a connection to h according to the sslmode parameter, as
+// pgx.ParseDSN does. TLS is never used over a Unix-domain socket.
+func (p *Params) configureTLS(cc *pgx.ConnConfig, h Host) error {
+	if strings.HasPrefix(cc.Host, "/") {
+		return nil
+	}
+	var config *tls.Config
+	switch mode := p.Get("sslmode"); mode {
+	case "disable":
+		return nil
+	case "allow":
+		config = &tls.Config{InsecureSkipVerify: true}
+		cc.UseFallbackTLS = true
+		cc.FallbackTLSConfig = config
+	case "prefer":
+		config = &tls.Config{InsecureSkipVerify: true}
+		cc.TLSConfig = config
+		cc.UseFallbackTLS = true
+	case "require":
+		config = &tls.Config{InsecureSkipVerify: true}
+		cc.TLSConfig = config
+	case "verify-ca", "verify-full":
+		config = &tls.Config{ServerName: h.Name}
+		cc.TLSConfig = config
+	default:
+		return fmt.Errorf("invalid sslmode %q", mode)
+	}
+	if path := p.Get("sslrootcert"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading sslrootcert: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("sslrootcert %s: no certificates found", path)
+		}
+	}
+	if certPath, keyPath := p.Get("sslcert"), p.Get("sslkey"); certPath != "" || keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return fmt.Errorf("loading sslcert and sslkey: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}
+
+// Connect tries each of p's hosts in turn, as libpq does, and returns the
+// configuration of the first one that accepts a connection and satisfies the
+// target_session_attrs parameter, along with where its password came from.
+// If that server demands a password and there is none, Connect calls prompt,
+// unless it is nil, to ask for one. Connect closes the connection it made to
+// check the server; the caller should connect again with the configuration.
+func (p *Params) Connect(prompt func() (string, error)) (pgx.ConnConfig, string, error) {
+	hosts, err := p.Hosts()
+	if err != nil {
+		return pgx.ConnConfig{}, "", err
+	}
+	attrs := p.Get("target_session_attrs")
+	passes := []string{attrs}
+	if attrs == "prefer-standby" {
+		passes = []string{"standby", "any"}
+	}
+	var failures []string
+	for _, want := range passes {
+		for _, h := range hosts {
+			cc, source, err := p.ConnConfig(h)
+			if err != nil {
+				return cc, "", err
+			}
+			err = checkServer(cc, want)
+			if isPasswordFailure(err) && cc.Password == "" && prompt != nil {
+				if cc.Password, err = prompt(); err != nil {
+					return cc, "", err
+				}
+				p.Set("password", cc.Password, "prompt")
+				source = "prompt"
+				err = checkServer(cc, want)
+			}
+			if err == nil {
+				return cc, source, nil
+			}
+			failures = append(failures, fmt.Sprintf("connection to server at %s failed: %v", h, err))
+		}
+	}
+	return pgx.ConnConfig{}, "", errors.New(strings.Join(failures, "\n"))
+}
+
+// Connects to the server configured by cc and checks that its session has
+// the given target_session_attrs.
+func checkServer(cc pgx.ConnConfig, attrs string) error {
+	conn, err := pgx.Connect(cc)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	switch attrs {
+	case "read-write", "read-only":
+		var readOnly string
+		if err := conn.QueryRow("show transaction_read_only").Scan(&readOnly); err != nil {
+			return err
+		}
+		if readOnly == "on" && attrs == "read-write" {
+			return fmt.Errorf("session is read-only")
+		}
+		if readOnly != "on" && attrs == "read-only" {
+			return fmt.Errorf("session is not read-only")
+		}
+	case "primary", "standby":
+		var inRecovery bool
+		if err := conn.QueryRow("select pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+			return err
+		}
+		if inRecovery && attrs == "primary" {
+			return fmt.Errorf("server is in hot standby mode")
+		}
+		if !inRecovery && attrs == "standby" {
+			return fmt.Errorf("server is not in hot standby mode")
+		}
+	}
+	return nil
+}
+
+// Reports whether err is the server's rejection of a password.
+func isPasswordFailure(err error) bool {
+	pgErr, ok := err.(pgx.PgError)
+	return ok && pgErr.Code == "28P01" // invalid_password
+}
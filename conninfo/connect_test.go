@@ -0,0 +1,114 @@
+package conninfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHosts(t *testing.T) {
+	tests := []struct {
+		connInfo string
+		want     []Host
+	}{
+		{"", []Host{{Name: "localhost", Port: 5432}}},
+		{"host=db1,db2 port=5433", []Host{{Name: "db1", Port: 5433}, {Name: "db2", Port: 5433}}},
+		{"host=db1,,db3 port=5433,5434,", []Host{{Name: "db1", Port: 5433}, {Name: "localhost", Port: 5434}, {Name: "db3", Port: 5432}}},
+		{"host=db1 hostaddr=10.0.0.1", []Host{{Name: "db1", Addr: "10.0.0.1", Port: 5432}}},
+		{"hostaddr=10.0.0.1,10.0.0.2", []Host{{Name: "10.0.0.1", Addr: "10.0.0.1", Port: 5432}, {Name: "10.0.0.2", Addr: "10.0.0.2", Port: 5432}}},
+	}
+	for _, tt := range tests {
+		p, err := Resolve(tt.connInfo, fakeEnv(nil))
+		if err != nil {
+			t.Errorf("Resolve(%q) failed: %v", tt.connInfo, err)
+			continue
+		}
+		got, err := p.Hosts()
+		if err != nil {
+			t.Errorf("Hosts() of %q failed: %v", tt.connInfo, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Hosts() of %q = %v, want %v", tt.connInfo, got, tt.want)
+		}
+	}
+}
+
+func TestConnConfig(t *testing.T) {
+	home := t.TempDir()
+	passfile := filepath.Join(home, ".pgpass")
+	if err := os.WriteFile(passfile, []byte("db2:5432:app:alice:from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := fakeEnv(map[string]string{"HOME": home, "PGAPPNAME": "pglint-test"})
+	p, err := Resolve("host=db1,db2 dbname=app user=alice sslmode=verify-full", env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := p.Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No password for db1; db2's is in the password file.
+	cc, source, err := p.ConnConfig(hosts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Password != "" || source != "" {
+		t.Errorf("db1: password %q from %q, want none", cc.Password, source)
+	}
+	cc, source, err = p.ConnConfig(hosts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Password != "from-file" || source != "password file "+passfile {
+		t.Errorf("db2: password %q from %q, want \"from-file\" from password file %s", cc.Password, source, passfile)
+	}
+	if cc.Host != "db2" || cc.Port != 5432 || cc.Database != "app" || cc.User != "alice" {
+		t.Errorf("db2: config %s@%s:%d/%s, want alice@db2:5432/app", cc.User, cc.Host, cc.Port, cc.Database)
+	}
+	if cc.TLSConfig == nil || cc.TLSConfig.ServerName != "db2" || cc.UseFallbackTLS {
+		t.Errorf("db2: TLS config %+v (fallback %v), want verification of db2", cc.TLSConfig, cc.UseFallbackTLS)
+	}
+	if got := cc.RuntimeParams["application_name"]; got != "pglint-test" {
+		t.Errorf("db2: application_name = %q, want \"pglint-test\"", got)
+	}
+
+	// A password in the parameters takes precedence.
+	p.Set("password", "given", "conninfo")
+	if cc, source, err = p.ConnConfig(hosts[1]); err != nil || cc.Password != "given" || source != "conninfo" {
+		t.Errorf("db2: password %q from %q (%v), want \"given\" from conninfo", cc.Password, source, err)
+	}
+}
+
+func TestConnectIgnoresExposedPassfile(t *testing.T) {
+	home := t.TempDir()
+	passfile := filepath.Join(home, ".pgpass")
+	if err := os.WriteFile(passfile, []byte("*:*:*:*:secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Resolve("host=127.0.0.1 port=1 dbname=app user=alice sslmode=disable connect_timeout=2", fakeEnv(map[string]string{"HOME": home}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := p.Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, source, err := p.ConnConfig(hosts[0])
+	if err != nil || cc.Password != "" || source != "" {
+		t.Errorf("got password %q from %q (%v), want none", cc.Password, source, err)
+	}
+
+	// Connect goes on to try the server, which is not there.
+	_, _, err = p.Connect(nil)
+	if err == nil || !strings.Contains(err.Error(), "connection to server at 127.0.0.1, port 1 failed") {
+		t.Errorf("Connect: got error %v, want a connection failure", err)
+	}
+	if w := p.Warnings(); len(w) != 1 || !strings.Contains(w[0], "group or world access") {
+		t.Errorf("got warnings %q, want one about the password file's access", w)
+	}
+}
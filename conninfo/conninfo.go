@@ -0,0 +1,326 @@
+// Package conninfo resolves Postgres connection parameters the way libpq
+// does: from a conninfo string or URI, then the service file, then the PG*
+// environment variables and finally the defaults, with passwords looked up
+// in the password file. It remembers where each parameter came from, which
+// is what to show a user wondering why pglint connected where it did.
+package conninfo
+
+import (
+	"fmt"
+	"net/url"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The connection parameters pglint understands, in libpq's order, with the
+// environment variables that supply them when neither the conninfo string
+// nor the service file does.
+var keywords = []struct {
+	name   string
+	envVar string
+}{
+	{"service", "PGSERVICE"},
+	{"host", "PGHOST"},
+	{"hostaddr", "PGHOSTADDR"},
+	{"port", "PGPORT"},
+	{"dbname", "PGDATABASE"},
+	{"user", "PGUSER"},
+	{"password", "PGPASSWORD"},
+	{"passfile", "PGPASSFILE"},
+	{"connect_timeout", "PGCONNECT_TIMEOUT"},
+	{"options", "PGOPTIONS"},
+	{"application_name", "PGAPPNAME"},
+	{"fallback_application_name", ""},
+	{"sslmode", "PGSSLMODE"},
+	{"sslcert", "PGSSLCERT"},
+	{"sslkey", "PGSSLKEY"},
+	{"sslrootcert", "PGSSLROOTCERT"},
+	{"target_session_attrs", "PGTARGETSESSIONATTRS"},
+}
+
+// Reports whether name is a connection parameter that pglint understands.
+func isKeyword(name string) bool {
+	for _, kw := range keywords {
+		if kw.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The values that sslmode and target_session_attrs may take.
+var (
+	sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+	targetSessionAttrs = []string{"any", "read-write", "read-only", "primary", "standby", "prefer-standby"}
+)
+
+// Params is a resolved set of connection parameters, keyed by their libpq
+// names, e.g. "host" and "dbname".
+type Params struct {
+	values   map[string]string
+	sources  map[string]string // where each value came from, e.g. "environment variable PGHOST"
+	home     string            // the user's home directory, for the default file paths
+	warnings []string          // q.v. Warnings
+}
+
+// Resolve completes the parameters given by connInfo, a conninfo string
+// ("host=db1 dbname=app") or URI ("postgresql://db1/app"), which may be
+// empty. A parameter missing from connInfo is taken from the service named
+// by the service parameter or $PGSERVICE, if any; then from its environment
+// variable, e.g. $PGHOST; then from its default. getenv looks up environment
+// variables, and is usually os.Getenv.
+//
+// Unlike libpq, the default host is "localhost" rather than a Unix-domain
+// socket, whose location varies between systems.
+func Resolve(connInfo string, getenv func(string) string) (*Params, error) {
+	p := &Params{
+		values:  make(map[string]string),
+		sources: make(map[string]string),
+		home:    getenv("HOME"),
+	}
+	given, err := parse(connInfo)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range given {
+		p.Set(name, value, "conninfo")
+	}
+	if p.Get("service") == "" {
+		if service := getenv("PGSERVICE"); service != "" {
+			p.Set("service", service, "environment variable PGSERVICE")
+		}
+	}
+	if service := p.Get("service"); service != "" {
+		if err := p.applyService(service, getenv); err != nil {
+			return nil, err
+		}
+	}
+	for _, kw := range keywords {
+		if p.Get(kw.name) != "" || kw.envVar == "" {
+			continue
+		}
+		if value := getenv(kw.envVar); value != "" {
+			p.Set(kw.name, value, "environment variable "+kw.envVar)
+		}
+	}
+	p.setDefaults(getenv)
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Fills in the parameters that have defaults and are still unset.
+func (p *Params) setDefaults(getenv func(string) string) {
+	def := func(name, value string) {
+		if p.Get(name) == "" && value != "" {
+			p.Set(name, value, "default")
+		}
+	}
+	def("host", "localhost")
+	def("port", "5432")
+	username := getenv("USER")
+	if u, err := user.Current(); username == "" && err == nil {
+		username = u.Username
+	}
+	def("user", username)
+	def("dbname", p.Get("user"))
+	if p.home != "" {
+		def("passfile", filepath.Join(p.home, ".pgpass"))
+	}
+	def("sslmode", "prefer")
+	def("target_session_attrs", "any")
+}
+
+// Checks the values of the parameters that have a fixed set of them.
+func (p *Params) validate() error {
+	if !contains(sslModes, p.Get("sslmode")) {
+		return fmt.Errorf("invalid sslmode %q (from %s)", p.Get("sslmode"), p.Source("sslmode"))
+	}
+	if !contains(targetSessionAttrs, p.Get("target_session_attrs")) {
+		return fmt.Errorf("invalid target_session_attrs %q (from %s)",
+			p.Get("target_session_attrs"), p.Source("target_session_attrs"))
+	}
+	if s := p.Get("connect_timeout"); s != "" {
+		if _, err := strconv.Atoi(s); err != nil {
+			return fmt.Errorf("invalid connect_timeout %q (from %s)", s, p.Source("connect_timeout"))
+		}
+	}
+	_, err := p.Hosts()
+	return err
+}
+
+// Get returns the value of the named parameter, or "" if it is unset.
+func (p *Params) Get(name string) string { return p.values[name] }
+
+// Source describes where the value of the named parameter came from, e.g.
+// "conninfo", "environment variable PGPORT", "service file
+// /home/u/.pg_service.conf" or "default".
+func (p *Params) Source(name string) string { return p.sources[name] }
+
+// Set sets the named parameter to value, which came from source.
+func (p *Params) Set(name, value, source string) {
+	p.values[name] = value
+	p.sources[name] = source
+}
+
+// Names returns the names of the parameters that are set, in libpq's order.
+func (p *Params) Names() []string {
+	var names []string
+	for _, kw := range keywords {
+		if _, ok := p.values[kw.name]; ok {
+			names = append(names, kw.name)
+		}
+	}
+	return names
+}
+
+// Parses a conninfo string or URI into a map of parameter values.
+func parse(s string) (map[string]string, error) {
+	if strings.HasPrefix(s, "postgresql://") || strings.HasPrefix(s, "postgres://") {
+		return parseURI(s)
+	}
+	return parseKeywords(s)
+}
+
+// Parses a conninfo string of keyword=value pairs separated by white space.
+// As in libpq, there may be white space around the "=", and a value may be
+// single-quoted; within a value, a backslash escapes the next character.
+func parseKeywords(s string) (map[string]string, error) {
+	params := make(map[string]string)
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("missing \"=\" after %q in connection info string", rest)
+		}
+		name := strings.TrimSpace(rest[:eq])
+		if !isKeyword(name) {
+			return nil, fmt.Errorf("invalid connection option %q", name)
+		}
+		rest = strings.TrimLeft(rest[eq+1:], " \t\n")
+		var value []byte
+		quoted := strings.HasPrefix(rest, "'")
+		if quoted {
+			rest = rest[1:]
+		}
+		i := 0
+		for ; i < len(rest); i++ {
+			c := rest[i]
+			if c == '\\' && i+1 < len(rest) {
+				i++
+				value = append(value, rest[i])
+				continue
+			}
+			if quoted && c == '\'' || !quoted && (c == ' ' || c == '\t' || c == '\n') {
+				break
+			}
+			value = append(value, c)
+		}
+		if quoted {
+			if i == len(rest) {
+				return nil, fmt.Errorf("unterminated quoted string in connection info string")
+			}
+			i++ // skip the closing quote
+		}
+		params[name] = string(value)
+		rest = strings.TrimLeft(rest[i:], " \t\n")
+	}
+	return params, nil
+}
+
+// Parses a URI of the form
+//
+//	postgresql://[user[:password]@][host][:port][,...][/dbname][?name=value[&...]]
+//
+// into a map of parameter values. Each part is percent-decoded.
+func parseURI(s string) (map[string]string, error) {
+	params := make(map[string]string)
+	rest := s[strings.Index(s, "://")+3:]
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		query, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI query %q: %v", rest[i+1:], err)
+		}
+		for name, values := range query {
+			if !isKeyword(name) {
+				return nil, fmt.Errorf("invalid URI query parameter %q", name)
+			}
+			params[name] = values[len(values)-1]
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		if err := setUnescaped(params, "dbname", rest[i+1:]); err != nil {
+			return nil, err
+		}
+		rest = rest[:i]
+	}
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 {
+		userinfo := rest[:i]
+		if j := strings.IndexByte(userinfo, ':'); j >= 0 {
+			if err := setUnescaped(params, "password", userinfo[j+1:]); err != nil {
+				return nil, err
+			}
+			userinfo = userinfo[:j]
+		}
+		if err := setUnescaped(params, "user", userinfo); err != nil {
+			return nil, err
+		}
+		rest = rest[i+1:]
+	}
+	if rest == "" {
+		return params, nil
+	}
+	var hosts, ports []string
+	for _, hostport := range strings.Split(rest, ",") {
+		host, port := hostport, ""
+		if strings.HasPrefix(host, "[") { // IPv6 address
+			end := strings.IndexByte(host, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing \"]\" in URI host %q", hostport)
+			}
+			host, port = host[1:end], strings.TrimPrefix(host[end+1:], ":")
+		} else if i := strings.LastIndexByte(host, ':'); i >= 0 {
+			host, port = host[:i], host[i+1:]
+		}
+		host, err := url.PathUnescape(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI host %q: %v", hostport, err)
+		}
+		hosts, ports = append(hosts, host), append(ports, port)
+	}
+	if _, ok := params["host"]; !ok {
+		params["host"] = strings.Join(hosts, ",")
+	}
+	if _, ok := params["port"]; !ok && strings.Join(ports, "") != "" {
+		params["port"] = strings.Join(ports, ",")
+	}
+	return params, nil
+}
+
+// Percent-decodes value and, if it is not empty, stores it under name unless
+// the query string already set it.
+func setUnescaped(params map[string]string, name, value string) error {
+	value, err := url.PathUnescape(value)
+	if err != nil {
+		return fmt.Errorf("invalid URI %s: %v", name, err)
+	}
+	if _, ok := params[name]; !ok && value != "" {
+		params[name] = value
+	}
+	return nil
+}
+
+// Reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
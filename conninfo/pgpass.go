@@ -0,0 +1,104 @@
+package conninfo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errPassfileIgnored is wrapped by the errors of lookupPassword that are only
+// warnings: as in libpq, the file is ignored, as if it did not exist.
+var errPassfileIgnored = errors.New("password file ignored")
+
+// Looks up the password for a connection in the password file at path, and
+// reports whether a line of it matched. As in libpq, the file is ignored if
+// it is not a plain file or if its group or others have any access to it; the
+// error then wraps errPassfileIgnored. A missing file is not an error.
+func lookupPassword(path, host, port, dbname, user string) (string, bool, error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", false, fmt.Errorf("%w: %q is not a plain file", errPassfileIgnored, path)
+	}
+	if fi.Mode().Perm()&0077 != 0 {
+		return "", false, fmt.Errorf("%w: %q has group or world access; permissions should be u=rw (0600) or less", errPassfileIgnored, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	password, found, err := matchPassword(f, host, port, dbname, user)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %v", path, err)
+	}
+	return password, found, nil
+}
+
+// Returns the password of the first line of a password file that matches
+// the connection. Each line has the form
+//
+//	hostname:port:database:username:password
+//
+// where any of the first four fields may be "*", which matches anything. A
+// backslash escapes a colon or backslash. Blank lines and comments, which
+// begin with "#", are ignored. Connections through a Unix-domain socket
+// match the hostname "localhost".
+func matchPassword(r io.Reader, host, port, dbname, user string) (string, bool, error) {
+	if strings.HasPrefix(host, "/") {
+		host = "localhost"
+	}
+	want := []string{host, port, dbname, user}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := splitPasswordLine(line)
+		if len(fields) < 5 {
+			continue // like libpq, skip malformed lines
+		}
+		match := true
+		for i, w := range want {
+			if fields[i] != "*" && fields[i] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return fields[4], true, nil
+		}
+	}
+	return "", false, sc.Err()
+}
+
+// Splits a line of a password file into its unescaped fields. The password,
+// which is the last field, may contain unescaped colons.
+func splitPasswordLine(line string) []string {
+	var (
+		fields []string
+		curr   []byte
+	)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			i++
+			curr = append(curr, line[i])
+		case c == ':' && len(fields) < 4:
+			fields = append(fields, string(curr))
+			curr = curr[:0]
+		default:
+			curr = append(curr, c)
+		}
+	}
+	return append(fields, string(curr))
+}
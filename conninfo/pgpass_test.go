@@ -0,0 +1,68 @@
+package conninfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPasswordFile = `# host:port:database:user:password
+db1:5432:app:alice:secret1
+db1:*:*:alice:secret2
+localhost:5432:*:*:local
+*:*:*:bob:with\:colon:and more
+`
+
+func TestMatchPassword(t *testing.T) {
+	tests := []struct {
+		host, port, dbname, user string
+		want                     string
+		found                    bool
+	}{
+		{"db1", "5432", "app", "alice", "secret1", true},
+		{"db1", "5433", "other", "alice", "secret2", true},
+		{"db2", "5432", "app", "alice", "", false},
+		{"/var/run/postgresql", "5432", "app", "carol", "local", true},
+		{"db9", "1", "x", "bob", "with:colon:and more", true},
+	}
+	for _, tt := range tests {
+		got, found, err := matchPassword(strings.NewReader(testPasswordFile), tt.host, tt.port, tt.dbname, tt.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want || found != tt.found {
+			t.Errorf("matchPassword(%s, %s, %s, %s) = %q, %v; want %q, %v",
+				tt.host, tt.port, tt.dbname, tt.user, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSplitPasswordLine(t *testing.T) {
+	got := splitPasswordLine(`a\\b:5432:d\:b:u:p:w`)
+	want := []string{`a\b`, "5432", "d:b", "u", "p:w"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitPasswordLine = %q, want %q", got, want)
+	}
+}
+
+func TestLookupPasswordPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pgpass")
+	if err := os.WriteFile(path, []byte("*:*:*:*:secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := lookupPassword(path, "db1", "5432", "app", "alice"); !errors.Is(err, errPassfileIgnored) {
+		t.Errorf("lookupPassword of a world-readable file: got error %v, want it ignored", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, found, err := lookupPassword(path, "db1", "5432", "app", "alice"); err != nil || !found || got != "secret" {
+		t.Errorf("lookupPassword = %q, %v, %v; want \"secret\", true, nil", got, found, err)
+	}
+	if _, found, err := lookupPassword(path+".missing", "db1", "5432", "app", "alice"); found || err != nil {
+		t.Errorf("lookupPassword of a missing file = %v, %v; want false, nil", found, err)
+	}
+}
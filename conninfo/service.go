@@ -0,0 +1,111 @@
+package conninfo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sets the unset parameters to those of the named service. As in libpq, the
+// service is looked up in the user's service file, $PGSERVICEFILE or
+// ~/.pg_service.conf, then in the system-wide one, pg_service.conf in
+// $PGSYSCONFDIR (by default /etc). It is an error if neither defines it.
+func (p *Params) applyService(service string, getenv func(string) string) error {
+	var paths []string
+	if path := getenv("PGSERVICEFILE"); path != "" {
+		paths = append(paths, path)
+	} else if p.home != "" {
+		paths = append(paths, filepath.Join(p.home, ".pg_service.conf"))
+	}
+	sysconfdir := getenv("PGSYSCONFDIR")
+	if sysconfdir == "" {
+		sysconfdir = "/etc"
+	}
+	paths = append(paths, filepath.Join(sysconfdir, "pg_service.conf"))
+	for _, path := range paths {
+		params, found, err := readServiceFile(path, service)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for name, value := range params {
+			if p.Get(name) == "" {
+				p.Set(name, value, "service file "+path)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("definition of service %q not found", service)
+}
+
+// Reads the parameters of the named service from the service file at path,
+// and reports whether the file defines the service. A missing file defines
+// no services.
+func readServiceFile(path, service string) (map[string]string, bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	params, found, err := readService(f, service)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %v", path, err)
+	}
+	return params, found, nil
+}
+
+// Parses a service file, which is an INI file with one section per service:
+//
+//	# comment
+//	[name]
+//	host=db1.example.com
+//	dbname=app
+//
+// It returns the parameters of the named service, and reports whether it was
+// defined. Services other than the named one are not checked for errors.
+func readService(r io.Reader, service string) (map[string]string, bool, error) {
+	var (
+		params map[string]string
+		found  bool
+		inside bool // whether the current section is the named service
+	)
+	sc := bufio.NewScanner(r)
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, false, fmt.Errorf("line %d: syntax error in service section %q", lineno, line)
+			}
+			inside = line[1:len(line)-1] == service
+			if inside && !found {
+				found = true
+				params = make(map[string]string)
+			}
+		case inside:
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return nil, false, fmt.Errorf("line %d: syntax error: missing \"=\" in %q", lineno, line)
+			}
+			name, value := strings.TrimSpace(line[:eq]), strings.TrimSpace(line[eq+1:])
+			switch {
+			case name == "service":
+				return nil, false, fmt.Errorf("line %d: nested service specifications are not supported", lineno)
+			case !isKeyword(name):
+				return nil, false, fmt.Errorf("line %d: invalid connection option %q", lineno, name)
+			}
+			params[name] = value
+		}
+	}
+	return params, found, sc.Err()
+}
@@ -0,0 +1,65 @@
+package conninfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testServiceFile = `
+# Services for the tests.
+[other]
+host=elsewhere
+
+[app]
+host = db1.example.com
+dbname=app
+  sslmode=verify-full
+`
+
+func TestReadService(t *testing.T) {
+	params, found, err := readService(strings.NewReader(testServiceFile), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"host": "db1.example.com", "dbname": "app", "sslmode": "verify-full"}
+	if !found || !reflect.DeepEqual(params, want) {
+		t.Errorf("readService = %v, %v; want %v, true", params, found, want)
+	}
+	if _, found, err := readService(strings.NewReader(testServiceFile), "missing"); found || err != nil {
+		t.Errorf("readService of a missing service = %v, %v; want false, nil", found, err)
+	}
+}
+
+func TestReadServiceErrors(t *testing.T) {
+	for _, file := range []string{
+		"[app\nhost=db1\n",
+		"[app]\nhost db1\n",
+		"[app]\nservice=other\n",
+		"[app]\nbogus=1\n",
+	} {
+		if _, _, err := readService(strings.NewReader(file), "app"); err == nil {
+			t.Errorf("readService(%q) succeeded, want an error", file)
+		}
+	}
+}
+
+func TestApplyServiceSystemFile(t *testing.T) {
+	home, sysconfdir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".pg_service.conf"), []byte("[other]\nhost=h1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(sysconfdir, "pg_service.conf")
+	if err := os.WriteFile(path, []byte("[app]\nhost=h2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Resolve("service=app", fakeEnv(map[string]string{"HOME": home, "PGSYSCONFDIR": sysconfdir}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, src := p.Get("host"), p.Source("host"); got != "h2" || src != "service file "+path {
+		t.Errorf("host = %q (from %s), want \"h2\" (from service file %s)", got, src, path)
+	}
+}
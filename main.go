@@ -1,14 +1,31 @@
 // pglint generates a report of problems found in a Postgres database. Its
 // heuristics depend on live data and statistics, so its output should therefore
 // only be trusted when run on a production instance.
+//
+// Usage:
+//
+//	pglint [flags]        print a report
+//	pglint serve [flags]  serve the findings as Prometheus metrics
+//	pglint tui [flags]    review the index findings interactively
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/dcowgill/pglint/conninfo"
+	"github.com/dcowgill/pglint/report"
 	"github.com/jackc/pgx"
 	"golang.org/x/text/language"
 )
@@ -18,14 +35,88 @@ import (
 func main() {
 	// Command-line flags.
 	var (
-		connInfo     = flag.String("conninfo", "host=localhost port=5432", "Postgres conninfo string or URI")
+		connInfo     = flag.String("conninfo", "", "Postgres conninfo string or URI; parameters it omits are taken from the PG* environment variables, the service file and the defaults, as in libpq")
 		namespace    = flag.String("namespace", "public", "schema to analyze")
-		verbose      = flag.Bool("verbose", false, "enable verbose logging")
+		verbose      = flag.Bool("verbose", false, "enable verbose logging, including the time each catalog query takes")
+		maxConns     = flag.Int("conns", 4, "max. connections with which to run catalog queries concurrently (at least 2)")
+		stmtTimeout  = flag.Duration("statementtimeout", 30*time.Second, "cancel any catalog query that runs longer than this (0 disables)")
+		lockTimeout  = flag.Duration("locktimeout", time.Second, "cancel any catalog query that waits longer than this for a lock (0 disables)")
 		unusedCutoff = flag.Int("unusedcutoff", 10, "treat indexes with this many scans or fewer as unused")
 		minIndexSize = flag.Int("minindexsize", 1, "min. size (MiB) for unused index to be included in report")
 		minIndexRows = flag.Int("minindexrows", 10, "min. rows for unused index to be included in report")
+		maxIndexes   = flag.Int("maxindexes", 10, "flag tables with more than this many indexes (0 disables)")
+		maxIdxRatio  = flag.Float64("maxindexratio", 1.5, "flag tables whose indexes exceed this multiple of the table's size (0 disables)")
+		minHotRatio  = flag.Float64("minhotratio", 0.5, "flag tables where fewer than this fraction of updates are HOT")
+		minUpdates   = flag.Int("minupdates", 1000, "min. updates for a table to be checked for HOT update blockers")
+		hotColumns   = flag.String("hotcolumns", check.DefaultUpdatedColumns, "regexp matching the names of columns that are likely updated")
+		maxDistinct  = flag.Int("maxdistinct", 10, "flag indexes whose leading column has at most this many distinct values")
+		minDistRows  = flag.Int("mindistinctrows", 10000, "min. rows for an index to be checked for low selectivity")
+		disableRules = flag.String("disablerules", "", "comma-separated column rules to skip: "+strings.Join(check.ColumnRuleNames(), ", "))
+		maxTinyVchar = flag.Int("maxtinyvarchar", 4, "flag varchar(n) columns where n is at most this")
+		largeTable   = flag.Int("largetablerows", 10000000, "flag int4 primary keys on tables with this many rows or inserts")
+		ssd          = flag.Bool("ssd", false, "the server's storage is solid-state: flag random_page_cost left at the default")
+		memory       = flag.Int("memory", 0, "the server's RAM (MiB), against which memory settings are judged (0 if unknown)")
+		rlsTables    = flag.String("rlstables", "", "comma-separated tables that must be protected by row-level security")
+		tenantCols   = flag.String("tenantcolumns", check.DefaultTenantColumns, "regexp matching the names of tenant columns, whose tables must be protected by row-level security")
+		maxRetained  = flag.Int("maxretainedwal", 1024, "flag replication slots retaining more than this much WAL (MiB)")
+		maxSlotXmin  = flag.Int("maxcatalogxminage", 10000000, "flag logical slots whose catalog_xmin is older than this many transactions")
+		maxLagBytes  = flag.Int("maxlagbytes", 256, "flag replicas more than this far behind (MiB)")
+		maxLagTime   = flag.Duration("maxlag", time.Minute, "flag replicas whose replay lag exceeds this")
+		runtime      = flag.Bool("runtime", false, "also report long transactions, lock chains and other live activity")
+		maxXactAge   = flag.Duration("maxxactage", 5*time.Minute, "runtime: flag transactions open longer than this")
+		maxIdleInTx  = flag.Duration("maxidleintx", time.Minute, "runtime: flag sessions idle in transaction longer than this")
+		maxPrepAge   = flag.Duration("maxpreparedage", 5*time.Minute, "runtime: flag prepared transactions older than this")
+		compareWith  = flag.String("compare", "", "report index drift against another database: a conninfo string or snapshot file")
+		snapshotPath = flag.String("snapshot", "", "save the schema's indexes to this file for later comparison")
+		allDatabases = flag.Bool("all-databases", false, "report on every database in the cluster that accepts connections, except the templates")
+		suppressPath = flag.String("suppress", "pglint.suppress", "file of findings to leave out of the report; tui: appends the items marked keep")
+		sqlOut       = flag.String("sqlout", "pglint-remediation.sql", "tui: file to write the statements for the items marked drop")
+		format       = flag.String("format", "markdown", "report format: markdown or html")
+		listenAddr   = flag.String("listen", ":9188", "serve: HTTP listen address")
+		refresh      = flag.Duration("refresh", 5*time.Minute, "serve: how often to reload the catalog")
 	)
-	flag.Parse()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: pglint [serve|tui] [flags]\n")
+		flag.PrintDefaults()
+	}
+	command, args := "report", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	flag.CommandLine.Parse(args) // exits on error
+	if command != "report" && command != "serve" && command != "tui" {
+		fatalf("unknown command %q", command)
+	}
+	if *format != "markdown" && *format != "html" {
+		fatalf("invalid report format %q: must be markdown or html", *format)
+	}
+	if *format == "html" && *compareWith != "" {
+		fatalf("the drift report is only available as markdown")
+	}
+	if *allDatabases && (command != "report" || *compareWith != "" || *snapshotPath != "") {
+		fatalf("-all-databases cannot be combined with -compare, -snapshot, serve or tui")
+	}
+	if *allDatabases && *format == "html" {
+		fatalf("the cluster report is only available as markdown")
+	}
+	updatedColumns, err := regexp.Compile(*hotColumns)
+	if err != nil {
+		fatalf("invalid -hotcolumns regexp: %v", err)
+	}
+	var tenantColumns *regexp.Regexp
+	if *tenantCols != "" {
+		if tenantColumns, err = regexp.Compile(*tenantCols); err != nil {
+			fatalf("invalid -tenantcolumns regexp: %v", err)
+		}
+	}
+	disabledRules, err := parseRuleNames(*disableRules)
+	if err != nil {
+		fatalf("invalid -disablerules: %v", err)
+	}
+	suppressions, err := check.ReadSuppressionFile(*suppressPath)
+	if err != nil {
+		fatalf("invalid -suppress file: %v", err)
+	}
 
 	// Determine the user's locale.
 	{
@@ -34,75 +125,580 @@ func main() {
 		if err != nil {
 			tag = language.English
 		}
-		setLanguage(tag)
+		report.SetLanguage(tag)
 	}
 
-	// Parse the connection string.
-	connConf, err := pgx.ParseConnectionString(*connInfo)
-	if err != nil {
-		fatalf("invalid Postgres conninfo string %q: %s", *connInfo, err)
+	// On the first interrupt, cancel the running queries, so that whatever has
+	// been loaded can still be reported; on the second, give up. The metrics
+	// server has no report to finish, so it just exits.
+	ctx, restoreInterrupt := context.Background(), func() {}
+	if command != "serve" {
+		ctx, restoreInterrupt = interruptible(ctx)
 	}
 
-	// Set the logging level for the underlying database driver.
-	connConf.LogLevel = pgx.LogLevelWarn
-	if *verbose {
-		connConf.LogLevel = pgx.LogLevelTrace
+	// Open a connection to the database.
+	dbOpts := dbOptions{
+		maxConns:         *maxConns,
+		statementTimeout: *stmtTimeout,
+		lockTimeout:      *lockTimeout,
+		verbose:          *verbose,
 	}
-
-	// For better errors, specify user and DB in lieu of implicit defaults.
-	if connConf.User == "" {
-		connConf.User = os.Getenv("USER")
+	pool, connConf := connect(*connInfo, dbOpts.maxConns, dbOpts.verbose)
+	db, err := newDB(ctx, pool, *namespace, dbOpts)
+	if err != nil {
+		fatalf("%+v", err)
 	}
-	if connConf.Database == "" {
-		connConf.Database = connConf.User
+
+	// Save a snapshot of the catalog, if requested. The server's live activity
+	// is only recorded with -runtime; its replication state always is.
+	if *snapshotPath != "" {
+		var src catalog.Source = db
+		if !*runtime {
+			// Hide the RuntimeSource methods.
+			src = struct {
+				catalog.Source
+				catalog.ReplicationSource
+			}{db, db}
+		}
+		if err := catalog.WriteSnapshot(ctx, *snapshotPath, src, connConf.Database, *namespace); err != nil {
+			fatalf("%+v", err)
+		}
 	}
 
-	// Open a connection to the database.
-	conn, err := pgx.Connect(connConf)
-	if err != nil {
-		fatalf("failed to connect: %s", err)
+	// In comparison mode, print a drift report instead of the usual one.
+	if *compareWith != "" {
+		if err := compare(ctx, db, describeConn(connConf), *compareWith, dbOpts); err != nil {
+			fatalf("%+v", err)
+		}
+		pool.Close()
+		return
 	}
 
-	// Fetch the info we need from the database and look for anomalies.
-	db := newDB(conn, *namespace)
-	allIndexes, err := db.allIndexes()
-	if err != nil {
-		fatalf("%+v", err)
+	// Generate and print a report, or serve metrics until killed.
+	opts := reportOptions{
+		unusedCutoff: *unusedCutoff,
+		minIndexSize: catalog.Bytes(*minIndexSize * catalog.MiB),
+		minIndexRows: *minIndexRows,
+		indexLimits:  check.IndexLimits{MaxIndexes: *maxIndexes, MaxIndexRatio: *maxIdxRatio},
+		hotOptions: check.HotOptions{
+			MinHotRatio:    *minHotRatio,
+			MinUpdates:     *minUpdates,
+			UpdatedColumns: updatedColumns,
+		},
+		selectivity: check.SelectivityOptions{MaxDistinct: *maxDistinct, MinRows: *minDistRows},
+		columns: check.ColumnOptions{
+			Disabled:       disabledRules,
+			MaxTinyVarchar: *maxTinyVchar,
+			LargeTableRows: *largeTable,
+		},
+		settings:     check.SettingsOptions{SSD: *ssd, Memory: catalog.Bytes(*memory * catalog.MiB)},
+		suppressions: suppressions,
+		rls:          check.RLSOptions{Tables: parseTableNames(*rlsTables), TenantColumns: tenantColumns},
+		replication: check.ReplicationOptions{
+			MaxRetainedWAL:    catalog.Bytes(*maxRetained * catalog.MiB),
+			MaxCatalogXminAge: *maxSlotXmin,
+			MaxLagBytes:       catalog.Bytes(*maxLagBytes * catalog.MiB),
+			MaxLagTime:        *maxLagTime,
+		},
 	}
-	duplicates, err := findDuplicateIndexSets(db)
-	if err != nil {
-		fatalf("%+v", err)
+	if *runtime {
+		opts.runtime = &check.RuntimeOptions{
+			MaxXactAge:     *maxXactAge,
+			MaxIdleInXact:  *maxIdleInTx,
+			MaxPreparedAge: *maxPrepAge,
+		}
+	}
+	if command == "serve" {
+		db.SetCacheTTL(*refresh)
+		fatalf("%+v", serve(*listenAddr, db, connConf, opts, *refresh))
+	}
+	if *allDatabases {
+		cr, err := buildClusterReport(ctx, db, connConf, opts, dbOpts)
+		if err != nil {
+			fatalf("%+v", err)
+		}
+		if err := cr.Generate(os.Stdout); err != nil {
+			fatalf("%+v", err)
+		}
+		pool.Close()
+		return
 	}
-	unused, err := findUnusedIndexes(db, *unusedCutoff)
+	rp, err := buildReport(ctx, db, connConf, opts)
 	if err != nil {
 		fatalf("%+v", err)
 	}
-	redundants, err := findRedundantIndexPairs(db)
-	if err != nil {
+	if command == "tui" {
+		// The findings are loaded, so Ctrl-C should leave the triage, as in
+		// any other command-line program.
+		restoreInterrupt()
+		if err := runTUI(rp, os.Stdin, os.Stdout, *sqlOut, *suppressPath); err != nil {
+			fatalf("%+v", err)
+		}
+		pool.Close()
+		return
+	}
+	generate := rp.Generate
+	if *format == "html" {
+		generate = rp.GenerateHTML
+	}
+	if err := generate(os.Stdout); err != nil {
 		fatalf("%+v", err)
 	}
 
-	// Generate and print a report.
-	rp := &reportPrinter{
+	// Close the connections.
+	pool.Close()
+}
+
+// reportOptions holds the thresholds that determine which indexes and tables
+// each check reports.
+type reportOptions struct {
+	unusedCutoff int                      // max. scans
+	minIndexSize catalog.Bytes            // min. size
+	minIndexRows int                      // min. rows
+	indexLimits  check.IndexLimits        // per-table limits
+	hotOptions   check.HotOptions         // HOT update blockers
+	selectivity  check.SelectivityOptions // low-selectivity indexes
+	columns      check.ColumnOptions      // column type rules
+	settings     check.SettingsOptions    // server configuration
+	rls          check.RLSOptions         // row-level security of tenant tables
+	replication  check.ReplicationOptions // replication slots and replicas
+	runtime      *check.RuntimeOptions    // live activity; nil to skip
+	suppressions check.Suppressions       // findings to leave out
+}
+
+// reportSource supplies everything a report is built from: usually a DB, but
+// a Snapshot will do.
+type reportSource interface {
+	catalog.Source
+	catalog.ReplicationSource
+	catalog.RuntimeSource
+}
+
+// Fetches the info we need from the database and looks for anomalies.
+func buildReport(ctx context.Context, src reportSource, connConf pgx.ConnConfig, opts reportOptions) (*report.Report, error) {
+	// Load the catalog concurrently up front; the checks then use the cache.
+	// If ctx is canceled, the checks run on whatever was loaded in time, and
+	// those that need anything else are listed as interrupted. A query that
+	// the server's version does not support fails again in the checks that
+	// need it, which are listed as unsupported.
+	var unsupportedErr *catalog.UnsupportedError
+	if db, ok := src.(*catalog.DB); ok {
+		if err := db.Load(ctx); err != nil && ctx.Err() == nil && !errors.As(err, &unsupportedErr) {
+			return nil, err
+		}
+	}
+	var interrupted, unsupported []string
+	skip := func(name string, err error) error {
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			interrupted = append(interrupted, name)
+		case errors.As(err, &unsupportedErr):
+			unsupported = append(unsupported, fmt.Sprintf("%s (requires Postgres %s)", name, unsupportedErr.MinVersion))
+		default:
+			return err
+		}
+		return nil
+	}
+	allIndexes, err := src.AllIndexes(ctx)
+	if err = skip("indexes", err); err != nil {
+		return nil, err
+	}
+	duplicates, err := check.DuplicateIndexSets(ctx, src)
+	if err = skip("duplicate indexes", err); err != nil {
+		return nil, err
+	}
+	unused, err := check.UnusedIndexes(ctx, src, opts.unusedCutoff)
+	if err = skip("unused indexes", err); err != nil {
+		return nil, err
+	}
+	redundants, err := check.RedundantIndexPairs(ctx, src)
+	if err = skip("redundant indexes", err); err != nil {
+		return nil, err
+	}
+	invalid, err := check.InvalidIndexes(ctx, src)
+	if err = skip("invalid indexes", err); err != nil {
+		return nil, err
+	}
+	partitionGaps, err := check.PartitionIndexGaps(ctx, src)
+	if err = skip("partition index gaps", err); err != nil {
+		return nil, err
+	}
+	tables, err := src.AllTables(ctx)
+	tablesLoaded := err == nil
+	if err = skip("tables", err); err != nil {
+		return nil, err
+	}
+	overIndexed, err := check.OverIndexedTables(ctx, src, opts.indexLimits)
+	if err = skip("over-indexed tables", err); err != nil {
+		return nil, err
+	}
+	hotBlockers, err := check.HotBlockers(ctx, src, opts.hotOptions)
+	if err = skip("HOT update blockers", err); err != nil {
+		return nil, err
+	}
+	lowSelectivity, err := check.LowSelectivityIndexes(ctx, src, opts.selectivity)
+	if err = skip("low-selectivity indexes", err); err != nil {
+		return nil, err
+	}
+	fkMismatches, err := check.ForeignKeyMismatches(ctx, src)
+	if err = skip("foreign key type mismatches", err); err != nil {
+		return nil, err
+	}
+	columnFindings, err := check.ColumnTypes(ctx, src, opts.columns)
+	if err = skip("column types", err); err != nil {
+		return nil, err
+	}
+	unvalidated, err := check.UnvalidatedConstraints(ctx, src)
+	if err = skip("unvalidated constraints", err); err != nil {
+		return nil, err
+	}
+	disabledTriggers, err := check.DisabledTriggers(ctx, src)
+	if err = skip("disabled triggers", err); err != nil {
+		return nil, err
+	}
+	settingProblems, err := check.SettingProblems(ctx, src, opts.settings)
+	if err = skip("server settings", err); err != nil {
+		return nil, err
+	}
+	securityFindings, err := check.SecurityAudit(ctx, src)
+	if err = skip("security audit", err); err != nil {
+		return nil, err
+	}
+	rlsGaps, err := check.RowSecurityGaps(ctx, src, opts.rls)
+	if err = skip("row-level security", err); err != nil {
+		return nil, err
+	}
+	var missingRLSTables []string
+	if tablesLoaded {
+		missingRLSTables = check.MissingTables(tables, opts.rls)
+	}
+	slots, err := src.ReplicationSlots(ctx)
+	if err = skip("replication slots", err); err != nil {
+		return nil, err
+	}
+	slotProblems := check.ReplicationSlotProblems(slots, opts.replication)
+	laggingReplicas, err := check.LaggingReplicas(ctx, src, opts.replication)
+	if err = skip("lagging replicas", err); err != nil {
+		return nil, err
+	}
+	var health *check.RuntimeHealth
+	var runtimeOpts check.RuntimeOptions
+	if opts.runtime != nil {
+		runtimeOpts = *opts.runtime
+		health, err = check.CheckRuntime(ctx, src, runtimeOpts)
+		if err = skip("runtime", err); err != nil {
+			return nil, err
+		}
+	}
+	duplicates = opts.suppressions.DuplicateSets(duplicates)
+	redundants = opts.suppressions.RedundantPairs(redundants)
+	invalid = opts.suppressions.Indexes("invalid", invalid)
+	lowSelectivity = opts.suppressions.LowSelectivityIndexes(lowSelectivity)
+	unused = opts.suppressions.Indexes("unused", unused)
+	return &report.Report{
 		ConnConfig:             connConf,
+		ServerVersion:          src.ServerVersion(),
 		AllIndexes:             allIndexes,
+		Tables:                 tables,
 		DuplicateIndexSets:     duplicates,
 		UnusedIndexes:          unused,
 		RedundantIndexPairs:    redundants,
-		UnusedIndexScansCutoff: *unusedCutoff,
-		MinIndexSize:           Bytes(*minIndexSize * 1024 * 1024),
-		MinIndexRowCount:       *minIndexRows,
+		InvalidIndexes:         invalid,
+		PartitionGaps:          partitionGaps,
+		OverIndexedTables:      overIndexed,
+		HotBlockers:            hotBlockers,
+		LowSelectivityIndexes:  lowSelectivity,
+		ForeignKeyMismatches:   fkMismatches,
+		ColumnFindings:         columnFindings,
+		UnvalidatedConstraints: unvalidated,
+		DisabledTriggers:       disabledTriggers,
+		SettingProblems:        settingProblems,
+		SecurityFindings:       securityFindings,
+		RLSGaps:                rlsGaps,
+		MissingRLSTables:       missingRLSTables,
+		ReplicationSlots:       slots,
+		SlotProblems:           slotProblems,
+		LaggingReplicas:        laggingReplicas,
+		UnusedIndexScansCutoff: opts.unusedCutoff,
+		MinIndexSize:           opts.minIndexSize,
+		MinIndexRowCount:       opts.minIndexRows,
+		IndexLimits:            opts.indexLimits,
+		HotOptions:             opts.hotOptions,
+		SelectivityOptions:     opts.selectivity,
+		ReplicationOptions:     opts.replication,
+		Runtime:                health,
+		RuntimeOptions:         runtimeOpts,
+		Interrupted:            interrupted,
+		Unsupported:            unsupported,
+		GeneratedAt:            time.Now(),
+	}, nil
+}
+
+// dbOptions holds the settings of each database connection pool and DB.
+type dbOptions struct {
+	maxConns         int           // max. connections per pool
+	statementTimeout time.Duration // statement_timeout of catalog queries
+	lockTimeout      time.Duration // lock_timeout of catalog queries
+	verbose          bool          // log driver messages and query times
+}
+
+// Creates a DB that reads namespace through pool, configured per opts.
+func newDB(ctx context.Context, pool *pgx.ConnPool, namespace string, opts dbOptions) (*catalog.DB, error) {
+	db, err := catalog.New(ctx, pool, namespace)
+	if err != nil {
+		return nil, err
 	}
-	if err := rp.generate(os.Stdout); err != nil {
-		fatalf("%+v", err)
+	db.SetTimeouts(opts.statementTimeout, opts.lockTimeout)
+	if opts.verbose {
+		db.SetQueryLogger(logQuery)
 	}
+	return db, nil
+}
 
-	// Close the connection.
-	if err := conn.Close(); err != nil {
-		fatalf("error while closing connection: %+v", err)
+// Checks every database in the cluster to which db belongs, connecting to
+// each in turn as connConf does, and combines the reports. A database that
+// cannot be checked is recorded in the report rather than failing it; once
+// ctx is canceled, the remaining databases are skipped.
+func buildClusterReport(ctx context.Context, db *catalog.DB, connConf pgx.ConnConfig, opts reportOptions, dbOpts dbOptions) (*report.ClusterReport, error) {
+	names, err := db.Databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cr := &report.ClusterReport{ConnConfig: connConf, ServerVersion: db.ServerVersion()}
+	for _, name := range names {
+		if ctx.Err() != nil {
+			cr.Unchecked = append(cr.Unchecked, name)
+			continue
+		}
+		rp, err := buildDatabaseReport(ctx, db, connConf, name, opts, dbOpts)
+		if err != nil {
+			cr.Failures = append(cr.Failures, report.DatabaseFailure{Database: name, Err: err})
+			continue
+		}
+		cr.AddDatabase(rp)
+	}
+	return cr, nil
+}
+
+// Builds the report for the named database of the cluster to which db
+// belongs, reusing db if it is connected to that database.
+func buildDatabaseReport(ctx context.Context, db *catalog.DB, connConf pgx.ConnConfig, name string, opts reportOptions, dbOpts dbOptions) (*report.Report, error) {
+	if name == connConf.Database {
+		return buildReport(ctx, db, connConf, opts)
+	}
+	connConf.Database = name
+	pool, err := openPool(connConf, dbOpts.maxConns)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+	other, err := newDB(ctx, pool, db.Namespace(), dbOpts)
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(ctx, other, connConf, opts)
+}
+
+// Resolves the connection parameters as libpq does, finds a server that
+// satisfies them, and opens a pool of up to maxConns connections to it,
+// aborting on failure. Returns the pool and the effective configuration of
+// its connections.
+func connect(connInfo string, maxConns int, verbose bool) (*pgx.ConnPool, pgx.ConnConfig) {
+	// Resolve the parameters and pick a server.
+	params, err := conninfo.Resolve(connInfo, os.Getenv)
+	if err != nil {
+		fatalf("invalid connection parameters: %s", err)
+	}
+	if verbose {
+		logParams(params)
+	}
+	connConf, passwordSource, err := params.Connect(passwordPrompt(params.Get("user")))
+	if verbose {
+		for _, w := range params.Warnings() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+	if err != nil {
+		fatalf("failed to connect: %s", err)
+	}
+	if verbose {
+		if passwordSource == "" {
+			passwordSource = "none"
+		}
+		fmt.Fprintf(os.Stderr, "connecting to %s, port %d; password from %s\n", connConf.Host, connConf.Port, passwordSource)
+	}
+
+	// Set the logging level for the underlying database driver.
+	connConf.LogLevel = pgx.LogLevelWarn
+	if verbose {
+		connConf.LogLevel = pgx.LogLevelTrace
+	}
+
+	// Open the pool, which connects to the database right away.
+	pool, err := openPool(connConf, maxConns)
+	if err != nil {
+		fatalf("failed to connect: %s", err)
+	}
+	return pool, connConf
+}
+
+// Opens a pool of up to maxConns connections configured by connConf.
+func openPool(connConf pgx.ConnConfig, maxConns int) (*pgx.ConnPool, error) {
+	if maxConns < 2 {
+		maxConns = 2 // pgx's minimum
+	}
+	return pgx.NewConnPool(pgx.ConnPoolConfig{ConnConfig: connConf, MaxConnections: maxConns})
+}
+
+// Prints each connection parameter and where it came from to stderr.
+func logParams(params *conninfo.Params) {
+	for _, name := range params.Names() {
+		value := params.Get(name)
+		if name == "password" {
+			value = "********"
+		}
+		fmt.Fprintf(os.Stderr, "connection parameter %s=%s (%s)\n", name, value, params.Source(name))
 	}
 }
 
+// Returns a function that asks the user for the password of the named
+// database user, or nil if stdin is not a terminal. The password is not
+// echoed, where stty can turn echoing off.
+func passwordPrompt(user string) func() (string, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return func() (string, error) {
+		fmt.Fprintf(os.Stderr, "Password for user %s: ", user)
+		if stty("-echo") == nil {
+			defer stty("echo")
+		}
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		fmt.Fprintln(os.Stderr)
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading password: %v", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+// Runs stty with the given argument on the terminal attached to stdin.
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// Returns a copy of ctx that is canceled when the process is first
+// interrupted. A second interrupt exits immediately. The returned function
+// restores the default handling of interrupts, which exits at the first.
+func interruptible(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		fmt.Fprintf(os.Stderr, "interrupted: canceling queries; interrupt again to exit now\n")
+		cancel()
+		<-sigs
+		os.Exit(130)
+	}()
+	return ctx, func() { signal.Stop(sigs) }
+}
+
+// Prints the name and duration of a catalog query to stderr.
+func logQuery(name string, elapsed time.Duration) {
+	fmt.Fprintf(os.Stderr, "query %q took %v\n", name, elapsed.Round(time.Millisecond))
+}
+
+// Describes a connection for display, e.g. "app@db.example.com:5432/app".
+func describeConn(c pgx.ConnConfig) string {
+	return fmt.Sprintf("%s@%s:%d/%s", c.User, c.Host, c.Port, c.Database)
+}
+
+// Compares the indexes in db with those of another database, which is either
+// a snapshot file or a conninfo string, and prints a drift report.
+func compare(ctx context.Context, db *catalog.DB, name, other string, opts dbOptions) error {
+	left, err := db.EveryIndex(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		rightSrc  catalog.Source
+		rightName string
+	)
+	if isFile(other) {
+		snap, err := catalog.ReadSnapshot(other)
+		if err != nil {
+			return err
+		}
+		if snap.Namespace != db.Namespace() {
+			return fmt.Errorf("snapshot %s is of schema %q, not %q", other, snap.Namespace, db.Namespace())
+		}
+		rightSrc = snap
+		rightName = fmt.Sprintf("snapshot %s of %q (%s)", other, snap.Database,
+			snap.TakenAt.Format(time.RFC1123))
+	} else {
+		pool, connConf := connect(other, opts.maxConns, opts.verbose)
+		defer pool.Close()
+		rightDB, err := newDB(ctx, pool, db.Namespace(), opts)
+		if err != nil {
+			return err
+		}
+		rightSrc = rightDB
+		rightName = describeConn(connConf)
+	}
+	right, err := rightSrc.EveryIndex(ctx)
+	if err != nil {
+		return err
+	}
+	dp := &report.DriftReport{
+		Left:  name,
+		Right: rightName,
+		Drift: check.FindSchemaDrift(left, right),
+	}
+	return dp.Generate(os.Stdout)
+}
+
+// Parses a comma-separated list of column rule names into a set, failing if
+// any name is unknown.
+func parseRuleNames(list string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	for _, name := range check.ColumnRuleNames() {
+		known[name] = true
+	}
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown column rule %q", name)
+		}
+		names[name] = true
+	}
+	return names, nil
+}
+
+// Parses a comma-separated list of table names into a set. A name in the
+// "public" namespace may be given with or without its namespace.
+func parseTableNames(list string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[strings.TrimPrefix(name, "public.")] = true
+		}
+	}
+	return names
+}
+
+// Reports whether path names an existing regular file.
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
 // Prints the message to stderr, then aborts.
 func fatalf(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format, args...)
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx"
+)
+
+// oldServer is a snapshot taken from a server too old to list replicas.
+type oldServer struct{ *catalog.Snapshot }
+
+func (s oldServer) Replicas(ctx context.Context) ([]*catalog.Replica, error) {
+	return nil, &catalog.UnsupportedError{What: "replicas", Version: s.ServerVersion(), MinVersion: 100000}
+}
+
+// brokenServer is a snapshot whose replicas cannot be listed for some reason
+// other than the server's version.
+type brokenServer struct{ *catalog.Snapshot }
+
+func (s brokenServer) Replicas(ctx context.Context) ([]*catalog.Replica, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestBuildReportUnsupported(t *testing.T) {
+	snap, err := catalog.ReadSnapshot("check/testdata/indexes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap.ServerVersionNum = 90600
+	ctx := context.Background()
+	rp, err := buildReport(ctx, oldServer{snap}, pgx.ConnConfig{Database: snap.Database}, reportOptions{})
+	if err != nil {
+		t.Fatalf("buildReport: %v", err)
+	}
+	if want := []string{"lagging replicas (requires Postgres 10.0)"}; strings.Join(rp.Unsupported, "|") != strings.Join(want, "|") {
+		t.Errorf("Unsupported: got %q, want %q", rp.Unsupported, want)
+	}
+	if len(rp.DuplicateIndexSets) == 0 {
+		t.Error("the supported checks found no duplicate indexes")
+	}
+	var b strings.Builder
+	if err := rp.Generate(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "not supported on\nPostgres 9.6.0") {
+		t.Error("the report does not list the unsupported checks")
+	}
+
+	// Errors unrelated to the server's version still abort the report.
+	if _, err := buildReport(ctx, brokenServer{snap}, pgx.ConnConfig{}, reportOptions{}); err == nil {
+		t.Error("buildReport ignored an error listing replicas")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	h := &metricsHandler{}
+	scrape := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		return w
+	}
+	if w := scrape(); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("before the first refresh: got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	h.metrics = []byte("pglint_rls_gaps{database=\"app\"} 2\n")
+	if w := scrape(); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "pglint_scrape_incomplete 0\n") {
+		t.Errorf("after a refresh: got status %d, body %q", w.Code, w.Body)
+	}
+	h.incomplete = true
+	if body := scrape().Body.String(); !strings.Contains(body, "pglint_rls_gaps") || !strings.Contains(body, "pglint_scrape_incomplete 1\n") {
+		t.Errorf("after a failed refresh: got body %q", body)
+	}
+}
@@ -0,0 +1,248 @@
+package report
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/jackc/pgx"
+)
+
+// ClusterReport combines the reports of every database in a cluster into one
+// markdown report, with a summary of the findings in each database. The
+// findings about the cluster as a whole are reported once, in Cluster, rather
+// than in each database's report.
+type ClusterReport struct {
+	ConnConfig    pgx.ConnConfig // of the connection through which the databases were listed
+	ServerVersion catalog.Version
+	Cluster       *Report           // the cluster's settings, roles, replication and runtime activity; nil until a database is added
+	Reports       []*Report         // one per database checked, in order of name
+	Failures      []DatabaseFailure // databases that could not be checked
+	Unchecked     []string          // databases not checked because pglint was interrupted
+}
+
+// AddDatabase adds the report of a database to the cluster report. The
+// findings about the cluster as a whole, which are the same in every
+// database's report, are taken from the first report added, and removed from
+// every report.
+func (cr *ClusterReport) AddDatabase(rp *Report) {
+	if cr.Cluster == nil {
+		cr.Cluster = &Report{
+			ConnConfig:         rp.ConnConfig,
+			ServerVersion:      rp.ServerVersion,
+			SettingProblems:    rp.SettingProblems,
+			ReplicationSlots:   rp.ReplicationSlots,
+			SlotProblems:       rp.SlotProblems,
+			LaggingReplicas:    rp.LaggingReplicas,
+			ReplicationOptions: rp.ReplicationOptions,
+			Runtime:            rp.Runtime,
+			RuntimeOptions:     rp.RuntimeOptions,
+			GeneratedAt:        rp.GeneratedAt,
+		}
+		for _, f := range rp.SecurityFindings {
+			if f.Role != nil {
+				cr.Cluster.SecurityFindings = append(cr.Cluster.SecurityFindings, f)
+			}
+		}
+	}
+	var findings []check.SecurityFinding
+	for _, f := range rp.SecurityFindings {
+		if f.Role == nil {
+			findings = append(findings, f)
+		}
+	}
+	rp.SecurityFindings = findings
+	rp.SettingProblems = nil
+	rp.ReplicationSlots = nil
+	rp.SlotProblems = nil
+	rp.LaggingReplicas = nil
+	rp.Runtime = nil
+	rp.InCluster = true
+	cr.Reports = append(cr.Reports, rp)
+}
+
+// DatabaseFailure records why a database of a cluster could not be checked.
+type DatabaseFailure struct {
+	Database string
+	Err      error
+}
+
+// Generate writes the report to w as markdown.
+func (cr *ClusterReport) Generate(w io.Writer) error {
+	return tmpl(w, markdownClusterReport, cr)
+}
+
+func (cr *ClusterReport) Now() string {
+	return time.Now().Format(time.RFC1123)
+}
+
+func (cr *ClusterReport) NumDatabases() int {
+	return len(cr.Reports) + len(cr.Failures) + len(cr.Unchecked)
+}
+
+// Incomplete reports whether any database was not checked, or not checked
+// fully, because pglint was interrupted.
+func (cr *ClusterReport) Incomplete() bool {
+	return len(cr.Unchecked) > 0 || len(cr.interruptedDatabases()) > 0
+}
+
+// Returns the names of the databases some of whose checks did not run
+// because pglint was interrupted.
+func (cr *ClusterReport) interruptedDatabases() []string {
+	var names []string
+	for _, rp := range cr.Reports {
+		if len(rp.Interrupted) > 0 {
+			names = append(names, rp.ConnConfig.Database)
+		}
+	}
+	return names
+}
+
+// FormatInterrupted lists the databases that were only partly checked, e.g.
+// "app, billing".
+func (cr *ClusterReport) FormatInterrupted() string {
+	return strings.Join(cr.interruptedDatabases(), ", ")
+}
+
+// FormatUnchecked lists the databases that were not checked at all.
+func (cr *ClusterReport) FormatUnchecked() string { return strings.Join(cr.Unchecked, ", ") }
+
+// ReclaimableBytes reports the disk space that would be freed by dropping the
+// indexes recommended in every database; q.v. Report.ReclaimableBytes.
+func (cr *ClusterReport) ReclaimableBytes() catalog.Bytes {
+	var total catalog.Bytes
+	for _, rp := range cr.Reports {
+		total += rp.ReclaimableBytes()
+	}
+	return total
+}
+
+func (cr *ClusterReport) FormatSummary() string { return cr.summaryTable().markdown() }
+
+// Returns a table with one row per database: the number of findings, and the
+// payoff of dropping the indexes the database's report recommends dropping.
+// The findings about the cluster as a whole have a row of their own.
+func (cr *ClusterReport) summaryTable() *table {
+	var (
+		rows  [][]interface{}
+		total savings
+		found int
+	)
+	if cr.Cluster != nil {
+		n := cr.Cluster.numFindings()
+		rows = append(rows, []interface{}{"(cluster)", n, 0, catalog.Bytes(0), 0})
+		found += n
+	}
+	for _, rp := range cr.Reports {
+		_, s := rp.sectionSavings()
+		n := rp.numFindings()
+		rows = append(rows, []interface{}{rp.ConnConfig.Database, n, s.Indexes, s.Bytes, s.Writes})
+		found += n
+		total.Indexes += s.Indexes
+		total.Bytes += s.Bytes
+		total.Writes += s.Writes
+	}
+	rows = append(rows, []interface{}{"Total", found, total.Indexes, total.Bytes, total.Writes})
+	headings := []string{"Database", "Findings", "Indexes to drop", "Reclaimable", "Index writes avoided"}
+	return &table{headings: headings, rows: rows}
+}
+
+func (cr *ClusterReport) NumFailures() int { return len(cr.Failures) }
+
+func (cr *ClusterReport) FormatFailures() string { return cr.failureTable().markdown() }
+
+func (cr *ClusterReport) failureTable() *table {
+	if cr.NumFailures() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(cr.Failures))
+	for i, f := range cr.Failures {
+		rows[i] = []interface{}{f.Database, f.Err.Error()}
+	}
+	return &table{headings: []string{"Database", "Error"}, rows: rows}
+}
+
+// FormatCluster renders the sections of the findings about the cluster as a
+// whole, with their headings one level deeper.
+func (cr *ClusterReport) FormatCluster() (string, error) {
+	if cr.Cluster == nil {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := tmpl(&b, markdownClusterSections, cr.Cluster); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// FormatDatabases renders the sections of each database's report, with their
+// headings one level deeper, under a heading naming the database.
+func (cr *ClusterReport) FormatDatabases() (string, error) {
+	var b strings.Builder
+	for _, rp := range cr.Reports {
+		b.WriteString("## Database \"" + rp.ConnConfig.Database + "\"\n")
+		if err := tmpl(&b, markdownDatabaseSections, rp); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// Returns the number of findings in all sections of the report.
+func (rp *Report) numFindings() int {
+	n := 0
+	for _, section := range rp.htmlPage().Sections {
+		n += section.Count
+	}
+	return n
+}
+
+// Adds a "#" to each heading.
+var demoteHeadings = regexp.MustCompile(`(?m)^#`)
+
+// The sections of a database's report, and those about the cluster as a whole,
+// with their headings demoted.
+var (
+	markdownDatabaseSections = demoteHeadings.ReplaceAllString(markdownSections, "##")
+	markdownClusterSections  = demoteHeadings.ReplaceAllString(
+		markdownSettingsSection+markdownSecuritySection+markdownReplicationSection+"\n"+markdownRuntimeSection, "##")
+)
+
+const markdownClusterReport = `# pglint report for cluster {{ .ConnConfig.Host }}:{{ .ConnConfig.Port }}
+
+Connection info:
+
+* Host: {{ .ConnConfig.Host }}
+* Port: {{ .ConnConfig.Port }}
+* User: {{ .ConnConfig.User }}
+* Server version: {{ .ServerVersion }}
+{{ if .Incomplete }}
+**This report is incomplete.** pglint was interrupted, so{{ if .FormatInterrupted }}
+some checks did not run in these databases: {{ .FormatInterrupted }}{{ if .Unchecked }};
+and{{ end }}{{ end }}{{ if .Unchecked }} these databases were not checked at all:
+{{ .FormatUnchecked }}{{ end }}.
+{{ end }}
+## Cluster Summary
+
+Databases checked: {{ len .Reports }} of {{ .NumDatabases }}
+
+Dropping the indexes recommended below would free {{ .ReclaimableBytes.Human }}
+of disk space across the cluster. Server settings, roles, replication and
+runtime activity are shared by the cluster, so they are reported once, under
+"Cluster"; each database's section below is the report pglint prints for that
+database alone, without them.
+
+{{ .FormatSummary }}
+{{ if .Failures }}
+Databases that could not be checked: {{ .NumFailures }}
+
+{{ .FormatFailures }}
+{{ end }}{{ if .Cluster }}
+## Cluster
+{{ .FormatCluster }}{{ end }}
+{{ .FormatDatabases }}
+*Generated at {{ .Now }}*
+`
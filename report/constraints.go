@@ -0,0 +1,103 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx"
+)
+
+func (rp *Report) NumForeignKeyMismatches() int       { return len(rp.ForeignKeyMismatches) }
+func (rp *Report) FormatForeignKeyMismatches() string { return rp.fkMismatchTable().markdown() }
+
+func (rp *Report) fkMismatchTable() *table {
+	if rp.NumForeignKeyMismatches() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.ForeignKeyMismatches))
+	for i, m := range rp.ForeignKeyMismatches {
+		fk := m.ForeignKey
+		rows[i] = []interface{}{
+			fk.Name(),
+			fk.QualifiedTableName(),
+			m.Columns.Column.Name,
+			describeColumn(m.Columns.Column),
+			fk.QualifiedRefTableName(),
+			m.Columns.RefColumn.Name,
+			describeColumn(m.Columns.RefColumn),
+			strings.Join(m.Problems, ", "),
+		}
+	}
+	headings := []string{"Constraint", "Table", "Column", "Type", "Ref. table", "Ref. column", "Ref. type", "Problem"}
+	return &table{headings: headings, rows: rows}
+}
+
+// Describes a column's type, collation and nullability, e.g.
+// `text collate "C" not null`.
+func describeColumn(c catalog.Column) string {
+	s := c.Type
+	if c.Collation != "" {
+		s += ` collate "` + c.Collation + `"`
+	}
+	if c.NotNull {
+		s += " not null"
+	}
+	return s
+}
+
+func (rp *Report) NumUnvalidatedConstraints() int { return len(rp.UnvalidatedConstraints) }
+func (rp *Report) FormatUnvalidatedConstraints() string {
+	return rp.unvalidatedConstraintTable().markdown()
+}
+
+func (rp *Report) unvalidatedConstraintTable() *table {
+	if rp.NumUnvalidatedConstraints() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.UnvalidatedConstraints))
+	for i, c := range rp.UnvalidatedConstraints {
+		rows[i] = []interface{}{
+			c.QualifiedTableName(),
+			c.Name(),
+			constraintKinds[c.Kind()],
+			c.Definition(),
+			"ALTER TABLE " + pgx.Identifier{c.Namespace(), c.TableName()}.Sanitize() +
+				" VALIDATE CONSTRAINT " + pgx.Identifier{c.Name()}.Sanitize() + ";",
+		}
+	}
+	headings := []string{"Table", "Constraint", "Kind", "Definition", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
+
+// Describes the kinds of constraint that can be NOT VALID.
+var constraintKinds = map[string]string{
+	"c": "check",
+	"f": "foreign key",
+	"n": "not null",
+}
+
+func (rp *Report) NumDisabledTriggers() int       { return len(rp.DisabledTriggers) }
+func (rp *Report) FormatDisabledTriggers() string { return rp.disabledTriggerTable().markdown() }
+
+func (rp *Report) disabledTriggerTable() *table {
+	if rp.NumDisabledTriggers() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.DisabledTriggers))
+	for i, tg := range rp.DisabledTriggers {
+		internal := ""
+		if tg.IsInternal() {
+			internal = "yes"
+		}
+		rows[i] = []interface{}{
+			tg.QualifiedTableName(),
+			tg.Name(),
+			tg.FunctionName(),
+			internal,
+			"ALTER TABLE " + pgx.Identifier{tg.Namespace(), tg.TableName()}.Sanitize() +
+				" ENABLE TRIGGER " + pgx.Identifier{tg.Name()}.Sanitize() + ";",
+		}
+	}
+	headings := []string{"Table", "Trigger", "Function", "Internal", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
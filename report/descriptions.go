@@ -0,0 +1,240 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// sectionDescriptions holds the prose that introduces each section of the
+// report, keyed by the section's ID. Each is a template, executed against the
+// Report, whose output is markdown; the HTML report converts it to HTML. Only
+// paragraphs, "*" lists, **bold** and _emphasis_ are converted.
+var sectionDescriptions = map[string]string{
+	"duplicate": `
+Indexes in this section share an exact definition with at least one other index.
+It is therefore always safe to drop all but one index in each set.`,
+
+	"redundant": `
+In the following table, "Index1" refers to the redundant index, and "Attrs1" its
+columns/expressions. It is usually safe to drop an index that is a prefix of
+another index, as the latter can satisfy the same query plans.`,
+
+	"invalid": `
+Indexes in this section cannot be used by queries. They are usually left behind
+by a failed CREATE INDEX CONCURRENTLY, although an index that is still being
+built concurrently also appears here. Apart from those being dropped, they are
+maintained on every write and occupy disk space. Drop them, or rebuild them if
+they are needed. (REINDEX CONCURRENTLY requires Postgres 12 or later; on older
+servers, the remedy drops the index and creates it again concurrently.)`,
+
+	"partitions": `
+Each partition in this section lacks an index that another partition of the same
+table has, typically because the index was created on individual partitions
+rather than on the partitioned table; queries that rely on it are fast on some
+partitions and slow on others. Throughout this report, the indexes of partitions
+are rolled up into the partitioned index they belong to, whose size, rows and
+scans are the totals across its partitions; likewise, partitions are rolled up
+into their partitioned table.`,
+
+	"overindexed": `
+Tables in this section have more than {{ .IndexLimits.MaxIndexes }} indexes, or
+indexes whose combined size exceeds {{ .IndexLimits.MaxIndexRatio }} times the
+size of the table. (A zero limit is not checked.) Every index slows down writes
+to its table; if few updates are HOT (heap-only tuple) updates, which leave the
+indexes untouched, the cost is all the greater. Review whether each index earns
+its keep.`,
+
+	"hot": `
+An update is a HOT (heap-only tuple) update if it modifies no indexed column
+and the new row version fits on the same page; it then leaves the table's
+indexes untouched. Tables in this section had at least {{ .HotOptions.MinUpdates }}
+updates, of which fewer than {{ .HotOptions.MinHotRatio }} were HOT. "Indexes"
+lists the indexes on columns that are likely updated, judging by their names.
+Consider dropping those indexes, or lowering the table's fill factor so that
+new row versions fit on the same page. (A lower fill factor only applies to
+pages written after the change.)`,
+
+	"lowselectivity": `
+These non-unique indexes lead with a column that has at most {{ .SelectivityOptions.MaxDistinct }}
+distinct values, judging by the planner's statistics, e.g. a boolean or a status
+column. The planner seldom prefers such an index to a sequential scan, except to
+find a rare value. If the most common value accounts for most rows, a partial
+index that excludes it is much smaller and just as useful; otherwise, consider
+dropping the index, or, for a multi-column index, reordering its columns.`,
+
+	"fkmismatch": `
+In each row, a foreign key column differs from the column it references. A
+type mismatch, e.g. integer referencing bigint, fails once the referenced
+values outgrow the narrower type, and any difference in type or collation can
+stop the planner from using an index to join the tables. A difference in
+nullability is also reported: a nullable column referencing a NOT NULL one is
+often an oversight, and in a multi-column MATCH SIMPLE foreign key, rows in
+which only some of the columns are null are not checked at all.`,
+
+	"columns": `
+Each row is a column whose type is a common source of schema review comments.
+"Rule" names the rule that flagged it; pass rule names to -disablerules to skip
+them.`,
+
+	"notvalid": `
+These constraints were added NOT VALID, typically by a migration that avoided
+a long lock, and never validated. They apply to new and updated rows only, so
+existing rows may violate them. Validating a constraint only takes a SHARE
+UPDATE EXCLUSIVE lock, which does not block reads or writes.`,
+
+	"triggers": `
+These triggers never fire, typically because they were disabled for a bulk load
+and never re-enabled. A disabled internal trigger means that a foreign key is
+not being enforced. Enabling a trigger does not apply it to rows written while
+it was disabled.`,
+
+	"settings": `
+Settings that put data or availability at risk are listed first. "Source" shows
+where the current value was set. ALTER SYSTEM writes postgresql.auto.conf; the
+new value takes effect after SELECT pg_reload_conf(), except for shared_buffers,
+which requires a restart. Memory sizes are judged against the machine's memory
+if it was given with -memory.`,
+
+	"security": `
+Each row is a role, grant or function that gives more access than is likely
+intended. Superusers and roles with CREATEROLE or BYPASSRLS should be few and
+well known. Passwords should expire, and be stored as SCRAM verifiers rather
+than md5 hashes; a new password is stored according to password_encryption, so
+the user must set it again. Any role can create objects in a schema on which
+PUBLIC has CREATE, and plant functions or operators that others call by
+mistake. A SECURITY DEFINER function runs with its owner's privileges, so it
+must pin its search_path, or a caller can substitute the objects it uses. The
+bootstrap superuser is not reported.`,
+
+	"rls": `
+A tenant table is one named with -rlstables, or with a column matching
+-tenantcolumns. Unless row-level security is enabled, every role with privileges
+on the table sees every tenant's rows; unless it is forced, so does the table's
+owner, which is often the role the application connects as. Each command needs a
+permissive policy: restrictive policies only narrow the access that permissive
+ones grant. Write the missing policies before enabling row-level security, or
+the table will appear empty.
+{{- if .MissingRLSTables }}
+
+**Tables not found:** {{ .FormatMissingRLSTables }}. These were named with
+-rlstables, but this database has no such tables, so they were not checked.
+{{- end }}`,
+
+	"slots": `
+A replication slot makes the server retain all WAL that its consumer has not yet
+received, even if the consumer is gone, until the disk fills up. Slots in this
+table have no consumer connected, retain more than {{ .ReplicationOptions.MaxRetainedWAL.Human }}
+of WAL, or, for logical slots, have a catalog_xmin more than {{ .ReplicationOptions.MaxCatalogXminAge }}
+transactions old, which stops vacuum from cleaning up the system catalogs. Drop
+a slot only once you are sure that its consumer is not coming back.`,
+
+	"replicas": `
+These standbys have yet to replay more than {{ .ReplicationOptions.MaxLagBytes.Human }}
+of WAL, or took longer than {{ .ReplicationOptions.MaxLagTime }} to replay recent
+WAL. Queries on a lagging standby see stale data. (Replay lag is only reported
+by Postgres 10 and later.)`,
+
+	"unused": `
+Criteria for inclusion in this report:
+
+* Scanned at most {{ .UnusedIndexScansCutoff }} times.
+* Size greater than or equal to {{ .MinIndexSize.Human }}.
+* Contains at least {{ .MinIndexRowCount }} rows.
+* Is either non-unique or is a primary key.
+
+**Important:** this section of the report relies on usage statistics, and will
+only contain meaningful results if pglint was run against a production database.
+
+Note: this report doesn't include unique indexes because its goal is to identify
+useless indexes, and a unique index can't be considered useless because it
+enforces a constraint. In other words, a unique index can't be dropped merely
+because the database never uses it to execute a query. (Note: when a unique
+index prevents its constraint from being violated, Postgres does not record that
+event as a "scan".) Primary key indexes, however, _are_ included, because a
+primary key that is never scanned is usually a sign of a data model design flaw.`,
+
+	"longxacts": `
+These transactions, in any database, were open when pglint ran. A long
+transaction prevents vacuum from removing dead rows, and holds its locks until
+it ends.`,
+
+	"idleinxact": `
+These sessions began a transaction and then stopped sending queries, often
+because of an application bug; like long transactions, they hold back vacuum
+and hold their locks. Setting idle_in_transaction_session_timeout makes the
+server end such sessions automatically.`,
+
+	"lockchains": `
+Each row is a session that holds a lock other sessions are waiting for,
+directly or through other waiting sessions, while not itself waiting.`,
+
+	"prepared": `
+A transaction prepared for two-phase commit holds its locks, and holds back
+vacuum, until it is committed or rolled back, even across a server restart.
+Old ones were usually abandoned by a failed transaction manager. Run the
+statement in the transaction's database, once you are sure it is not needed.`,
+}
+
+// Parsed once, since the descriptions are constant.
+var descriptionTemplates = func() map[string]*template.Template {
+	m := make(map[string]*template.Template, len(sectionDescriptions))
+	for id, text := range sectionDescriptions {
+		m[id] = template.Must(template.New(id).Parse(strings.TrimSpace(text)))
+	}
+	return m
+}()
+
+// Describe returns the description of the section with the given ID, as
+// markdown.
+func (rp *Report) Describe(id string) (string, error) {
+	t, ok := descriptionTemplates[id]
+	if !ok {
+		return "", fmt.Errorf("no description for section %q", id)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, rp); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Returns the description of the section with the given ID, as HTML. The
+// descriptions are constant, so an error is a bug.
+func (rp *Report) describeHTML(id string) htmltemplate.HTML {
+	md, err := rp.Describe(id)
+	if err != nil {
+		panic(err)
+	}
+	return markdownToHTML(md)
+}
+
+var (
+	strongMarkup   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	emphasisMarkup = regexp.MustCompile(`\b_([^_]+)_\b`)
+)
+
+// Converts the limited markdown of a section description to HTML.
+func markdownToHTML(md string) htmltemplate.HTML {
+	inline := func(s string) string {
+		s = htmltemplate.HTMLEscapeString(s)
+		s = strongMarkup.ReplaceAllString(s, "<strong>$1</strong>")
+		return emphasisMarkup.ReplaceAllString(s, "<em>$1</em>")
+	}
+	var b strings.Builder
+	for _, para := range strings.Split(md, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(para), "\n")
+		if !strings.HasPrefix(lines[0], "* ") {
+			b.WriteString("<p>" + inline(strings.Join(lines, "\n")) + "</p>\n")
+			continue
+		}
+		b.WriteString("<ul>\n")
+		for _, line := range lines {
+			b.WriteString("<li>" + inline(strings.TrimPrefix(line, "* ")) + "</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	return htmltemplate.HTML(b.String())
+}
@@ -0,0 +1,167 @@
+package report
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+)
+
+// DriftReport renders a check.SchemaDrift as a markdown report.
+type DriftReport struct {
+	Left  string // describes the left side, e.g. a database name
+	Right string // describes the right side
+	Drift *check.SchemaDrift
+}
+
+// Generate writes the report to w.
+func (dp *DriftReport) Generate(w io.Writer) error {
+	return tmpl(w, markdownDriftReport, dp)
+}
+
+func (dp *DriftReport) Now() string {
+	return time.Now().Format(time.RFC1123)
+}
+
+func (dp *DriftReport) Identical() bool { return dp.Drift.Empty() }
+
+func (dp *DriftReport) NumMissingOnLeft() int  { return len(dp.Drift.MissingOnLeft) }
+func (dp *DriftReport) NumMissingOnRight() int { return len(dp.Drift.MissingOnRight) }
+func (dp *DriftReport) NumChanged() int        { return len(dp.Drift.Changed) }
+func (dp *DriftReport) NumInvalid() int {
+	return len(dp.Drift.InvalidOnLeft) + len(dp.Drift.InvalidOnRight)
+}
+
+func (dp *DriftReport) FormatMissingOnLeft() string {
+	return missingIndexesTable(dp.Drift.MissingOnLeft)
+}
+
+func (dp *DriftReport) FormatMissingOnRight() string {
+	return missingIndexesTable(dp.Drift.MissingOnRight)
+}
+
+func (dp *DriftReport) FormatChanged() string {
+	if dp.NumChanged() == 0 {
+		return ""
+	}
+	sortIndexDiffsByName(dp.Drift.Changed)
+	rows := make([][]interface{}, len(dp.Drift.Changed))
+	for i, diff := range dp.Drift.Changed {
+		l, r := diff.Left, diff.Right
+		rows[i] = []interface{}{
+			l.QualifiedTableName(),
+			l.Name(),
+			l.Kind(),
+			strings.Join(l.Attrs(), ", "),
+			r.Kind(),
+			strings.Join(r.Attrs(), ", "),
+		}
+	}
+	headings := []string{"Table", "Index", "T (left)", "Attrs (left)", "T (right)", "Attrs (right)"}
+	return pprintTableString(headings, rows, "")
+}
+
+func (dp *DriftReport) FormatInvalid() string {
+	if dp.NumInvalid() == 0 {
+		return ""
+	}
+	describe := func(ind *catalog.Index) string {
+		if ind == nil {
+			return "missing"
+		}
+		return ind.State()
+	}
+	diffs := append(append([]check.IndexDiff(nil), dp.Drift.InvalidOnLeft...), dp.Drift.InvalidOnRight...)
+	sortIndexDiffsByName(diffs)
+	rows := make([][]interface{}, len(diffs))
+	for i, diff := range diffs {
+		ind := diff.Either()
+		rows[i] = []interface{}{
+			ind.QualifiedTableName(),
+			ind.Name(),
+			describe(diff.Left),
+			describe(diff.Right),
+		}
+	}
+	headings := []string{"Table", "Index", "Left", "Right"}
+	return pprintTableString(headings, rows, "")
+}
+
+// Formats indexes present on only one side, including their definitions so
+// that the reader can recreate them.
+func missingIndexesTable(indexes []*catalog.Index) string {
+	if len(indexes) == 0 {
+		return ""
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return lessByTableAndName(indexes[i], indexes[j])
+	})
+	rows := make([][]interface{}, len(indexes))
+	for i, ind := range indexes {
+		rows[i] = []interface{}{
+			ind.QualifiedTableName(),
+			ind.Name(),
+			ind.Kind(),
+			int(ind.Size().MiB()),
+			ind.Definition(),
+		}
+	}
+	headings := []string{"Table", "Index", "T", "Size (MiB)", "Definition"}
+	return pprintTableString(headings, rows, "")
+}
+
+// Sorts diffs by the table and name of their first non-nil index.
+func sortIndexDiffsByName(a []check.IndexDiff) {
+	sort.Slice(a, func(i, j int) bool { return lessByTableAndName(a[i].Either(), a[j].Either()) })
+}
+
+// Orders indexes by qualified table name, then by index name.
+func lessByTableAndName(ind1, ind2 *catalog.Index) bool {
+	if t1, t2 := ind1.QualifiedTableName(), ind2.QualifiedTableName(); t1 != t2 {
+		return t1 < t2
+	}
+	return ind1.Name() < ind2.Name()
+}
+
+const markdownDriftReport = `# pglint schema drift report
+
+* Left: {{ .Left }}
+* Right: {{ .Right }}
+{{ if .Identical }}
+No differences found: both sides have equivalent indexes.
+{{ else }}
+## Missing on the Right
+
+Indexes found on the left but not the right: {{ .NumMissingOnRight }}
+
+{{ .FormatMissingOnRight }}
+
+## Missing on the Left
+
+Indexes found on the right but not the left: {{ .NumMissingOnLeft }}
+
+{{ .FormatMissingOnLeft }}
+
+## Changed Definitions
+
+Indexes with the same name but different definitions: {{ .NumChanged }}
+
+Two indexes are considered the same if they are on the same table, have the same
+uniqueness, columns/expressions, collations, operator classes, options and
+predicate. The text of their definitions is not compared.
+
+{{ .FormatChanged }}
+
+## Invalid Indexes
+
+Indexes that are invalid, not ready or not live on one side only: {{ .NumInvalid }}
+
+These are usually left behind by a failed CREATE INDEX CONCURRENTLY.
+
+{{ .FormatInvalid }}
+{{ end }}
+*Generated at {{ .Now }}*
+`
@@ -0,0 +1,386 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// htmlPage is the data for the HTML report template.
+type htmlPage struct {
+	*Report
+	Sections []*htmlSection
+	Total    savings // of dropping every section's candidates
+}
+
+// htmlSection is one collapsible section of the HTML report.
+type htmlSection struct {
+	ID          string
+	Title       string
+	Count       int
+	Description template.HTML
+	Tables      []*htmlTable
+	Savings     savings // of dropping the section's candidates
+}
+
+// htmlTable is a table, converted for rendering as HTML.
+type htmlTable struct {
+	Headings []string
+	Numeric  []bool // for each column, whether it should be right-aligned
+	Rows     []htmlRow
+}
+
+type htmlRow struct {
+	Hover string // tooltip for the row, if any
+	Cells []htmlCell
+}
+
+type htmlCell struct {
+	Text    string
+	SortKey string // for numeric cells, the unformatted number
+}
+
+// Converts a table for use in the HTML template. A nil table converts to nil.
+func (t *table) html() *htmlTable {
+	if t == nil {
+		return nil
+	}
+	ht := &htmlTable{
+		Headings: t.headings,
+		Numeric:  make([]bool, len(t.headings)),
+		Rows:     make([]htmlRow, len(t.rows)),
+	}
+	for i := range t.headings {
+		ht.Numeric[i] = true
+		for _, row := range t.rows {
+			if i < len(row) && !isNumeric(row[i]) {
+				ht.Numeric[i] = false
+				break
+			}
+		}
+	}
+	for i, row := range t.rows {
+		cells := make([]htmlCell, len(t.headings))
+		for j := 0; j < len(row) && j < len(t.headings); j++ {
+			cells[j].Text = formatCell(row[j])
+			if isNumeric(row[j]) {
+				cells[j].SortKey = fmt.Sprint(row[j])
+			}
+		}
+		ht.Rows[i].Cells = cells
+		if i < len(t.hovers) {
+			ht.Rows[i].Hover = t.hovers[i]
+		}
+	}
+	return ht
+}
+
+// Collects the report's sections for the HTML template.
+func (rp *Report) htmlPage() *htmlPage {
+	tables := func(ts ...*table) []*htmlTable {
+		var hts []*htmlTable
+		for _, t := range ts {
+			if t != nil {
+				hts = append(hts, t.html())
+			}
+		}
+		return hts
+	}
+	page := &htmlPage{
+		Report: rp,
+		Sections: []*htmlSection{
+			{
+				ID:          "duplicate",
+				Title:       "Duplicate Indexes",
+				Count:       rp.NumDuplicateIndexSets(),
+				Description: rp.describeHTML("duplicate"),
+				Tables:      tables(rp.duplicateIndexTables()...),
+			},
+			{
+				ID:          "redundant",
+				Title:       "Redundant Indexes",
+				Count:       rp.NumRedundantIndexPairs(),
+				Description: rp.describeHTML("redundant"),
+				Tables:      tables(rp.redundantIndexTable()),
+			},
+			{
+				ID:          "invalid",
+				Title:       "Invalid Indexes",
+				Count:       rp.NumInvalidIndexes(),
+				Description: rp.describeHTML("invalid"),
+				Tables:      tables(rp.invalidIndexTable()),
+			},
+			{
+				ID:          "partitions",
+				Title:       "Partition Index Gaps",
+				Count:       rp.NumPartitionGaps(),
+				Description: rp.describeHTML("partitions"),
+				Tables:      tables(rp.partitionGapTable()),
+			},
+			{
+				ID:          "overindexed",
+				Title:       "Over-Indexed Tables",
+				Count:       rp.NumOverIndexedTables(),
+				Description: rp.describeHTML("overindexed"),
+				Tables:      tables(rp.overIndexedTable()),
+			},
+			{
+				ID:          "hot",
+				Title:       "HOT Update Blockers",
+				Count:       rp.NumHotBlockers(),
+				Description: rp.describeHTML("hot"),
+				Tables:      tables(rp.hotBlockerTable()),
+			},
+			{
+				ID:          "lowselectivity",
+				Title:       "Low-Selectivity Indexes",
+				Count:       rp.NumLowSelectivityIndexes(),
+				Description: rp.describeHTML("lowselectivity"),
+				Tables:      tables(rp.lowSelectivityTable()),
+			},
+			{
+				ID:          "fkmismatch",
+				Title:       "Foreign Key Mismatches",
+				Count:       rp.NumForeignKeyMismatches(),
+				Description: rp.describeHTML("fkmismatch"),
+				Tables:      tables(rp.fkMismatchTable()),
+			},
+			{
+				ID:          "columns",
+				Title:       "Column Types",
+				Count:       rp.NumColumnFindings(),
+				Description: rp.describeHTML("columns"),
+				Tables:      tables(rp.columnFindingTable()),
+			},
+			{
+				ID:          "notvalid",
+				Title:       "NOT VALID Constraints",
+				Count:       rp.NumUnvalidatedConstraints(),
+				Description: rp.describeHTML("notvalid"),
+				Tables:      tables(rp.unvalidatedConstraintTable()),
+			},
+			{
+				ID:          "triggers",
+				Title:       "Disabled Triggers",
+				Count:       rp.NumDisabledTriggers(),
+				Description: rp.describeHTML("triggers"),
+				Tables:      tables(rp.disabledTriggerTable()),
+			},
+			{
+				ID:          "settings",
+				Title:       "Server Settings",
+				Count:       rp.NumSettingProblems(),
+				Description: rp.describeHTML("settings"),
+				Tables:      tables(rp.settingTable()),
+			},
+			{
+				ID:          "security",
+				Title:       "Security",
+				Count:       rp.NumSecurityFindings(),
+				Description: rp.describeHTML("security"),
+				Tables:      tables(rp.securityTable()),
+			},
+			{
+				ID:          "rls",
+				Title:       "Row-Level Security",
+				Count:       rp.NumRLSGaps(),
+				Description: rp.describeHTML("rls"),
+				Tables:      tables(rp.rlsTable()),
+			},
+			{
+				ID:          "slots",
+				Title:       "Replication Slots",
+				Count:       rp.NumSlotProblems(),
+				Description: rp.describeHTML("slots"),
+				Tables:      tables(rp.slotProblemTable()),
+			},
+			{
+				ID:          "replicas",
+				Title:       "Lagging Replicas",
+				Count:       rp.NumLaggingReplicas(),
+				Description: rp.describeHTML("replicas"),
+				Tables:      tables(rp.laggingReplicaTable()),
+			},
+			{
+				ID:          "unused",
+				Title:       "Unused Indexes",
+				Count:       rp.NumUnusedIndexes(),
+				Description: rp.describeHTML("unused"),
+				Tables:      tables(rp.unusedIndexTable()),
+			},
+		},
+	}
+	if rp.Runtime != nil {
+		page.Sections = append(page.Sections,
+			&htmlSection{
+				ID:          "longxacts",
+				Title:       "Long Transactions",
+				Count:       rp.NumLongTransactions(),
+				Description: rp.describeHTML("longxacts"),
+				Tables:      tables(rp.longTransactionTable()),
+			},
+			&htmlSection{
+				ID:          "idleinxact",
+				Title:       "Idle in Transaction",
+				Count:       rp.NumIdleInTransaction(),
+				Description: rp.describeHTML("idleinxact"),
+				Tables:      tables(rp.idleInTransactionTable()),
+			},
+			&htmlSection{
+				ID:          "lockchains",
+				Title:       "Lock Chains",
+				Count:       rp.NumLockChains(),
+				Description: rp.describeHTML("lockchains"),
+				Tables:      tables(rp.lockChainTable()),
+			},
+			&htmlSection{
+				ID:          "prepared",
+				Title:       "Orphaned Prepared Transactions",
+				Count:       rp.NumOrphanedPrepared(),
+				Description: rp.describeHTML("prepared"),
+				Tables:      tables(rp.orphanedPreparedTable()),
+			},
+		)
+	}
+	bySection, total := rp.sectionSavings()
+	for _, sec := range page.Sections {
+		sec.Savings = bySection[sec.ID]
+	}
+	page.Total = total
+	return page
+}
+
+// htmlTmpl is like tmpl but uses html/template, which escapes its input.
+func htmlTmpl(w io.Writer, text string, data interface{}) error {
+	t := template.New("top").Funcs(template.FuncMap{
+		"human": func(b catalog.Bytes) string { return b.Human() },
+		"int":   fmtInt,
+	})
+	template.Must(t.Parse(text))
+	ew := &errWriter{w: w}
+	return ew.result(t.Execute(ew, data))
+}
+
+const htmlReport = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>pglint report for database "{{ .ConnConfig.Database }}"</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+dl.conn { display: grid; grid-template-columns: max-content auto; gap: 0.2em 1em; }
+dl.conn dt { font-weight: bold; }
+table { border-collapse: collapse; margin: 0.5em 0 1.5em; font-size: 0.9em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f3f3f3; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+td.num, th.num { text-align: right; }
+tr[title] { cursor: help; }
+tbody tr:hover { background: #fafae0; }
+details { margin: 1em 0; border-top: 1px solid #ddd; padding-top: 0.5em; }
+summary { cursor: pointer; }
+summary h2 { display: inline; font-size: 1.3em; }
+.count { color: #666; }
+.summary td.num { font-weight: bold; }
+input.filter { margin-top: 0.5em; padding: 0.2em; width: 20em; }
+p.incomplete { padding: 0.5em; background: #fde8e8; border: 1px solid #e0a0a0; }
+footer { margin-top: 2em; color: #666; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>pglint report for database "{{ .ConnConfig.Database }}"</h1>
+<dl class="conn">
+<dt>Host</dt><dd>{{ .ConnConfig.Host }}</dd>
+<dt>Port</dt><dd>{{ .ConnConfig.Port }}</dd>
+<dt>User</dt><dd>{{ .ConnConfig.User }}</dd>
+<dt>Database</dt><dd>{{ .ConnConfig.Database }}</dd>
+<dt>Server version</dt><dd>{{ .ServerVersion }}</dd>
+</dl>
+{{- if .Interrupted }}
+<p class="incomplete"><strong>This report is incomplete.</strong> pglint was
+interrupted before it could run the following checks, whose sections are
+therefore empty: {{ .FormatInterrupted }}.</p>
+{{- end }}
+{{- if .Unsupported }}
+<p class="incomplete"><strong>Some checks were skipped.</strong> The following
+checks are not supported on Postgres {{ .ServerVersion }}, so their sections
+are empty: {{ .FormatUnsupported }}.</p>
+{{- end }}
+
+<h2>Summary</h2>
+<table class="summary">
+<thead><tr><th>Check</th><th class="num">Findings</th><th class="num">Indexes to drop</th><th class="num">Reclaimable</th><th class="num">Index writes avoided</th></tr></thead>
+<tbody>
+{{- range .Sections }}
+<tr><td><a href="#{{ .ID }}">{{ .Title }}</a></td><td class="num">{{ .Count }}</td><td class="num">{{ .Savings.Indexes }}</td><td class="num">{{ human .Savings.Bytes }}</td><td class="num">{{ int .Savings.Writes }}</td></tr>
+{{- end }}
+</tbody>
+<tfoot><tr><td>Total</td><td></td><td class="num">{{ .Total.Indexes }}</td><td class="num">{{ human .Total.Bytes }}</td><td class="num">{{ int .Total.Writes }}</td></tr></tfoot>
+</table>
+<p>Index writes avoided are estimated from table activity since statistics were
+last reset. An index that appears in several sections is counted once in the
+total.</p>
+
+{{ range .Sections -}}
+<details id="{{ .ID }}" open>
+<summary><h2>{{ .Title }}</h2> <span class="count">({{ .Count }})</span></summary>
+{{ .Description }}
+{{ range .Tables -}}
+{{ $table := . -}}
+<input class="filter" type="search" placeholder="Filter rows&hellip;">
+<table class="sortable">
+<thead><tr>
+{{- range $i, $h := .Headings }}<th{{ if index $table.Numeric $i }} class="num"{{ end }}>{{ $h }}</th>{{ end -}}
+</tr></thead>
+<tbody>
+{{- range .Rows }}
+<tr{{ if .Hover }} title="{{ .Hover }}"{{ end }}>
+{{- range .Cells }}<td{{ if .SortKey }} class="num" data-sort="{{ .SortKey }}"{{ end }}>{{ .Text }}</td>{{ end -}}
+</tr>
+{{- end }}
+</tbody>
+</table>
+{{ end -}}
+</details>
+{{ end }}
+<footer>Generated at {{ .Now }}. Hover over a row to see the index definition; click a heading to sort.</footer>
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  var tbody = table.tBodies[0];
+  table.querySelectorAll("th").forEach(function (th, col) {
+    th.addEventListener("click", function () {
+      var asc = !th.classList.contains("asc");
+      table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+      th.classList.add(asc ? "asc" : "desc");
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[col], y = b.cells[col], c;
+        if (x.dataset.sort !== undefined && y.dataset.sort !== undefined) {
+          c = parseFloat(x.dataset.sort) - parseFloat(y.dataset.sort);
+        } else {
+          c = x.textContent.localeCompare(y.textContent);
+        }
+        return asc ? c : -c;
+      });
+      rows.forEach(function (r) { tbody.appendChild(r); });
+    });
+  });
+});
+document.querySelectorAll("input.filter").forEach(function (input) {
+  var tbody = input.nextElementSibling.tBodies[0];
+  input.addEventListener("input", function () {
+    var q = input.value.toLowerCase();
+    Array.prototype.forEach.call(tbody.rows, function (r) {
+      r.style.display = r.textContent.toLowerCase().indexOf(q) >= 0 ? "" : "none";
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
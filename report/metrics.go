@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+)
+
+// GenerateMetrics writes the report to w in the Prometheus text exposition
+// format: one gauge per check, plus size and scan series for every index.
+func (rp *Report) GenerateMetrics(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	db := rp.ConnConfig.Database
+	gauge := func(name, help string, value interface{}) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		fmt.Fprintf(bw, "%s{database=%s} %v\n", name, quoteLabel(db), value)
+	}
+	gauge("pglint_duplicate_index_sets", "Number of sets of duplicate indexes.", rp.NumDuplicateIndexSets())
+	gauge("pglint_redundant_index_pairs", "Number of indexes made redundant by another index.", rp.NumRedundantIndexPairs())
+	gauge("pglint_unused_indexes", "Number of unused indexes that meet the report's size and row thresholds.", rp.NumUnusedIndexes())
+	gauge("pglint_invalid_indexes", "Number of invalid, not ready or not live indexes.", rp.NumInvalidIndexes())
+	gauge("pglint_partition_index_gaps", "Number of indexes missing from a partition that its siblings have.", rp.NumPartitionGaps())
+	gauge("pglint_over_indexed_tables", "Number of tables exceeding the index count or size limits.", rp.NumOverIndexedTables())
+	gauge("pglint_hot_blocked_tables", "Number of tables with a low ratio of HOT updates.", rp.NumHotBlockers())
+	gauge("pglint_low_selectivity_indexes", "Number of non-unique indexes leading with a column with few distinct values.", rp.NumLowSelectivityIndexes())
+	gauge("pglint_foreign_key_mismatches", "Number of foreign key columns that differ from the columns they reference.", rp.NumForeignKeyMismatches())
+	gauge("pglint_column_type_findings", "Number of columns with questionable types.", rp.NumColumnFindings())
+	gauge("pglint_unvalidated_constraints", "Number of NOT VALID constraints.", rp.NumUnvalidatedConstraints())
+	gauge("pglint_disabled_triggers", "Number of disabled triggers.", rp.NumDisabledTriggers())
+	gauge("pglint_setting_problems", "Number of risky or suboptimal server settings.", rp.NumSettingProblems())
+	gauge("pglint_security_findings", "Number of roles, grants and functions that weaken security.", rp.NumSecurityFindings())
+	gauge("pglint_rls_gaps", "Number of tenant tables with row-level security gaps.", rp.NumRLSGaps())
+	gauge("pglint_replication_slot_problems", "Number of inactive replication slots or slots retaining too much WAL.", rp.NumSlotProblems())
+	gauge("pglint_lagging_replicas", "Number of replicas lagging behind the thresholds.", rp.NumLaggingReplicas())
+	if rp.Runtime != nil {
+		gauge("pglint_long_transactions", "Number of transactions open longer than the threshold.", rp.NumLongTransactions())
+		gauge("pglint_idle_in_transaction_sessions", "Number of sessions idle in transaction longer than the threshold.", rp.NumIdleInTransaction())
+		gauge("pglint_lock_chains", "Number of sessions blocking other sessions without being blocked themselves.", rp.NumLockChains())
+		gauge("pglint_orphaned_prepared_transactions", "Number of prepared transactions older than the threshold.", rp.NumOrphanedPrepared())
+	}
+	gauge("pglint_reclaimable_bytes", "Disk space freed by dropping every superfluous index.", int64(rp.ReclaimableBytes()))
+
+	// Per-index series, in a stable order.
+	indexes := append([]*catalog.Index(nil), rp.AllIndexes...)
+	sort.Slice(indexes, func(i, j int) bool { return lessByTableAndName(indexes[i], indexes[j]) })
+	labels := func(ind *catalog.Index) string {
+		return fmt.Sprintf("database=%s,schema=%s,table=%s,index=%s",
+			quoteLabel(db), quoteLabel(ind.Namespace()), quoteLabel(ind.TableName()), quoteLabel(ind.Name()))
+	}
+	fmt.Fprint(bw, "# HELP pglint_index_size_bytes Size of the index on disk.\n# TYPE pglint_index_size_bytes gauge\n")
+	for _, ind := range indexes {
+		fmt.Fprintf(bw, "pglint_index_size_bytes{%s} %d\n", labels(ind), int64(ind.Size()))
+	}
+	fmt.Fprint(bw, "# HELP pglint_index_scans_total Number of index scans since statistics were reset.\n# TYPE pglint_index_scans_total counter\n")
+	for _, ind := range indexes {
+		fmt.Fprintf(bw, "pglint_index_scans_total{%s} %d\n", labels(ind), ind.NumScans())
+	}
+	fmt.Fprint(bw, "# HELP pglint_replication_slot_retained_bytes WAL retained for the replication slot.\n# TYPE pglint_replication_slot_retained_bytes gauge\n")
+	for _, s := range rp.ReplicationSlots {
+		fmt.Fprintf(bw, "pglint_replication_slot_retained_bytes{database=%s,slot=%s} %d\n",
+			quoteLabel(db), quoteLabel(s.Name()), int64(s.RetainedWAL()))
+	}
+	return bw.Flush()
+}
+
+// Quotes a Prometheus label value, escaping it as the exposition format
+// requires.
+func quoteLabel(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx"
+)
+
+func (rp *Report) NumPartitionGaps() int       { return len(rp.PartitionGaps) }
+func (rp *Report) FormatPartitionGaps() string { return rp.partitionGapTable().markdown() }
+
+func (rp *Report) partitionGapTable() *table {
+	if rp.NumPartitionGaps() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.PartitionGaps))
+	hovers := make([]string, len(rp.PartitionGaps))
+	for i, g := range rp.PartitionGaps {
+		rows[i] = []interface{}{
+			g.Partition.QualifiedName(),
+			g.Partition.ParentName(),
+			g.Index.QualifiedTableName(),
+			g.Index.Name(),
+			strings.Join(g.Index.Attrs(), ", "),
+			createIndexLike(g.Partition, g.Index),
+		}
+		hovers[i] = g.Index.Definition()
+	}
+	headings := []string{"Partition", "Parent", "Sibling", "Sibling's index", "Attrs", "Statement"}
+	return &table{headings, rows, hovers}
+}
+
+// Returns a statement that creates an index on t like ind, letting Postgres
+// choose its name. The index method, operator classes and so on are copied
+// from ind's definition, if it is known.
+func createIndexLike(t *catalog.Table, ind *catalog.Index) string {
+	stmt := "CREATE INDEX CONCURRENTLY ON "
+	if ind.IsUnique() {
+		stmt = "CREATE UNIQUE INDEX CONCURRENTLY ON "
+	}
+	stmt += pgx.Identifier{t.Namespace(), t.Name()}.Sanitize()
+	if def := ind.Definition(); strings.Contains(def, " USING ") {
+		return stmt + def[strings.Index(def, " USING "):] + ";"
+	}
+	stmt += " (" + strings.Join(ind.Attrs(), ", ") + ")"
+	if ind.Pred() != "" {
+		stmt += " WHERE " + ind.Pred()
+	}
+	return stmt + ";"
+}
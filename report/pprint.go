@@ -1,4 +1,4 @@
-package main
+package report
 
 import (
 	"bytes"
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dcowgill/pglint/catalog"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -25,22 +26,7 @@ func pprintTable(w io.Writer, headers []string, rows [][]interface{}, prefix str
 	for i, row := range rows {
 		strs := make([]string, len(row))
 		for j, cell := range row {
-			var s string
-			switch v := cell.(type) {
-			case fmt.Stringer:
-				s = v.String()
-			case fmt.GoStringer:
-				s = v.GoString()
-			case string:
-				s = v
-			case int:
-				s = fmtInt(v)
-			case float64:
-				s = fmtFloat(v)
-			default:
-				s = fmt.Sprintf("%v", cell)
-			}
-			strs[j] = s
+			strs[j] = formatCell(cell)
 		}
 		strRows[i] = strs
 	}
@@ -51,9 +37,10 @@ func pprintTable(w io.Writer, headers []string, rows [][]interface{}, prefix str
 nextCol:
 	for i := range headers {
 		for _, row := range rows {
-			switch row[i].(type) {
-			case int, float64:
-			default:
+			if i >= len(row) {
+				continue // padding doesn't affect alignment
+			}
+			if !isNumeric(row[i]) {
 				alignLeft[i] = true
 				continue nextCol
 			}
@@ -124,6 +111,35 @@ nextCol:
 	}
 }
 
+// Converts a table cell to a string; q.v. pprintTable.
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case catalog.Bytes:
+		return v.Human()
+	case fmt.Stringer:
+		return v.String()
+	case fmt.GoStringer:
+		return v.GoString()
+	case string:
+		return v
+	case int:
+		return fmtInt(v)
+	case float64:
+		return fmtFloat(v)
+	}
+	return fmt.Sprintf("%v", cell)
+}
+
+// Reports whether a table cell holds a number, which should be right-aligned.
+// A catalog.Bytes is shown in human-readable units, but sorts as a number.
+func isNumeric(cell interface{}) bool {
+	switch cell.(type) {
+	case int, float64, catalog.Bytes:
+		return true
+	}
+	return false
+}
+
 // Like pprintTable but returns the output as a string.
 func pprintTableString(headers []string, rows [][]interface{}, prefix string) string {
 	buf := new(bytes.Buffer)
@@ -150,6 +166,7 @@ func fmtFloat(n float64) string {
 // The global locale-specific printer.
 var msgPrinter *message.Printer
 
-func setLanguage(lang language.Tag) {
+// SetLanguage sets the language used to format numbers in reports.
+func SetLanguage(lang language.Tag) {
 	msgPrinter = message.NewPrinter(lang)
 }
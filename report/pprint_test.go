@@ -0,0 +1,48 @@
+package report
+
+import "testing"
+
+func TestPprintTable(t *testing.T) {
+	msgPrinter = nil // format numbers without locale
+	headers := []string{"Name", "Rows", "Ratio"}
+	rows := [][]interface{}{
+		{"users", 5000, 0.5},
+		{"orders_with_long_name", 12, 1.25},
+	}
+	want := "" +
+		"| Name                  | Rows |    Ratio |\n" +
+		"| :-------------------- | ---: | -------: |\n" +
+		"| users                 | 5000 | 0.500000 |\n" +
+		"| orders_with_long_name |   12 | 1.250000 |\n"
+	if got := pprintTableString(headers, rows, ""); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPprintTableMixedColumnIsLeftAligned(t *testing.T) {
+	msgPrinter = nil
+	headers := []string{"Value"}
+	rows := [][]interface{}{{1}, {"two"}}
+	want := "" +
+		"> | Value |\n" +
+		"> | :---- |\n" +
+		"> | 1     |\n" +
+		"> | two   |\n"
+	if got := pprintTableString(headers, rows, "> "); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPprintTableShortRowsArePadded(t *testing.T) {
+	msgPrinter = nil
+	headers := []string{"A", "B"}
+	rows := [][]interface{}{{"x", 1, "ignored"}, {"z"}}
+	want := "" +
+		"| A | B |\n" +
+		"| : | : |\n" +
+		"| x | 1 |\n" +
+		"| z |   |\n"
+	if got := pprintTableString(headers, rows, ""); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
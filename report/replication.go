@@ -0,0 +1,60 @@
+package report
+
+import (
+	"strings"
+)
+
+func (rp *Report) NumSlotProblems() int       { return len(rp.SlotProblems) }
+func (rp *Report) FormatSlotProblems() string { return rp.slotProblemTable().markdown() }
+
+func (rp *Report) slotProblemTable() *table {
+	if rp.NumSlotProblems() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.SlotProblems))
+	for i, p := range rp.SlotProblems {
+		s := p.Slot
+		active := "no"
+		if s.IsActive() {
+			active = "yes"
+		}
+		statement := ""
+		if !s.IsActive() {
+			statement = "SELECT pg_drop_replication_slot(" + quoteLiteral(s.Name()) + ");"
+		}
+		rows[i] = []interface{}{
+			s.Name(),
+			s.Type(),
+			s.Database(),
+			active,
+			s.RetainedWAL(),
+			s.CatalogXminAge(),
+			strings.Join(p.Problems, ", "),
+			statement,
+		}
+	}
+	headings := []string{"Slot", "Type", "Database", "Active", "Retained WAL", "catalog_xmin age", "Problem", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
+
+func (rp *Report) NumLaggingReplicas() int       { return len(rp.LaggingReplicas) }
+func (rp *Report) FormatLaggingReplicas() string { return rp.laggingReplicaTable().markdown() }
+
+func (rp *Report) laggingReplicaTable() *table {
+	if rp.NumLaggingReplicas() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.LaggingReplicas))
+	for i, r := range rp.LaggingReplicas {
+		rows[i] = []interface{}{
+			r.ApplicationName(),
+			r.ClientAddr(),
+			r.State(),
+			r.SyncState(),
+			r.LagBytes(),
+			fmtDuration(r.ReplayLag()),
+		}
+	}
+	headings := []string{"Replica", "Address", "State", "Sync", "Lag", "Replay lag"}
+	return &table{headings: headings, rows: rows}
+}
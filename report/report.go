@@ -0,0 +1,673 @@
+// Package report renders the findings of package check: as a markdown or HTML
+// report for a database, a markdown report for every database of a cluster,
+// or Prometheus metrics. It also renders the schema drift between two
+// databases, and lists the index findings to triage interactively.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/jackc/pgx"
+)
+
+// Report holds the findings for a database and renders them as markdown or
+// HTML. The unused index thresholds are only used to describe, and filter, the
+// unused indexes; the other options only describe the corresponding sections.
+type Report struct {
+	ConnConfig             pgx.ConnConfig
+	ServerVersion          catalog.Version
+	AllIndexes             []*catalog.Index
+	Tables                 []*catalog.Table
+	DuplicateIndexSets     []check.DuplicateSet
+	UnusedIndexes          []*catalog.Index
+	RedundantIndexPairs    []check.RedundantPair
+	InvalidIndexes         []*catalog.Index
+	PartitionGaps          []check.PartitionGap
+	OverIndexedTables      []check.TableRollup
+	HotBlockers            []check.HotBlocker
+	LowSelectivityIndexes  []check.LowSelectivityIndex
+	ForeignKeyMismatches   []check.FKMismatch
+	ColumnFindings         []check.ColumnFinding
+	UnvalidatedConstraints []*catalog.Constraint
+	DisabledTriggers       []*catalog.Trigger
+	SettingProblems        []check.SettingFinding
+	SecurityFindings       []check.SecurityFinding
+	RLSGaps                []check.RLSGap
+	MissingRLSTables       []string // tables named with -rlstables that do not exist
+	ReplicationSlots       []*catalog.ReplicationSlot
+	SlotProblems           []check.SlotProblem
+	LaggingReplicas        []*catalog.Replica
+	UnusedIndexScansCutoff int
+	MinIndexSize           catalog.Bytes
+	MinIndexRowCount       int
+	IndexLimits            check.IndexLimits
+	HotOptions             check.HotOptions
+	SelectivityOptions     check.SelectivityOptions
+	ReplicationOptions     check.ReplicationOptions
+	Runtime                *check.RuntimeHealth // nil unless runtime checks were requested
+	RuntimeOptions         check.RuntimeOptions
+	Interrupted            []string  // checks that did not run because pglint was interrupted
+	Unsupported            []string  // checks that did not run because the server is too old for them
+	GeneratedAt            time.Time // when the findings were collected; if zero, when the report is rendered
+	InCluster              bool      // part of a ClusterReport, which reports the findings about the cluster once
+
+	relevantUnusedIndexes []*catalog.Index // cache
+}
+
+// Generate writes the report to w as markdown.
+func (rp *Report) Generate(w io.Writer) error {
+	return tmpl(w, markdownReport, rp)
+}
+
+// GenerateHTML writes the report to w as a self-contained HTML page.
+func (rp *Report) GenerateHTML(w io.Writer) error {
+	return htmlTmpl(w, htmlReport, rp.htmlPage())
+}
+
+func (rp *Report) Now() string {
+	return rp.generatedAt().Format(time.RFC1123)
+}
+
+// Returns the time the report describes, from which dates in its remedies are
+// computed.
+func (rp *Report) generatedAt() time.Time {
+	if rp.GeneratedAt.IsZero() {
+		return time.Now()
+	}
+	return rp.GeneratedAt
+}
+
+// FormatInterrupted lists the checks that did not run, e.g. "unused indexes,
+// security audit".
+func (rp *Report) FormatInterrupted() string { return strings.Join(rp.Interrupted, ", ") }
+
+// FormatUnsupported lists the checks that the server's version does not
+// support, e.g. "lagging replicas (requires Postgres 10.0)".
+func (rp *Report) FormatUnsupported() string { return strings.Join(rp.Unsupported, ", ") }
+
+func (rp *Report) NumDuplicateIndexSets() int { return len(rp.DuplicateIndexSets) }
+func (rp *Report) FormatDuplicateIndexSets() string {
+	var b strings.Builder
+	sep := ""
+	for _, t := range rp.duplicateIndexTables() {
+		_, _ = b.WriteString(sep)
+		_, _ = b.WriteString(t.markdown())
+		sep = "\n\n"
+	}
+	return b.String()
+}
+
+func (rp *Report) duplicateIndexTables() []*table {
+	sortIndexSetsByName(rp.DuplicateIndexSets)
+	var tables []*table
+	for _, indexes := range rp.DuplicateIndexSets {
+		tables = append(tables, indexesTable(indexes))
+	}
+	return tables
+}
+
+func sortIndexSetsByName(sets []check.DuplicateSet) {
+	// Sort the indexs within each set by name.
+	for _, indexes := range sets {
+		sort.Sort(indexesByName(indexes))
+	}
+	// Then sort the sets by table name.
+	sort.Slice(sets, func(i, j int) bool {
+		ind1, ind2 := sets[i][0], sets[j][0]
+		if ind1.TableName() == ind2.TableName() {
+			return ind1.Name() < ind2.Name() // tie-breaker
+		}
+		return ind1.TableName() == ind2.TableName()
+	})
+}
+
+func (rp *Report) NumUnusedIndexes() int       { return len(rp.getRelevantUnusedIndexes()) }
+func (rp *Report) FormatUnusedIndexes() string { return rp.unusedIndexTable().markdown() }
+
+func (rp *Report) unusedIndexTable() *table {
+	if rp.NumUnusedIndexes() == 0 {
+		return nil
+	}
+	return indexesTable(rp.getRelevantUnusedIndexes())
+}
+
+func (rp *Report) getRelevantUnusedIndexes() []*catalog.Index {
+	if rp.relevantUnusedIndexes == nil {
+		indexes := catalog.FilterIndexes(rp.UnusedIndexes, func(ind *catalog.Index) bool {
+			switch {
+			case ind.Kind() == catalog.UniqueIndex:
+				return false
+			case ind.Size() < rp.MinIndexSize:
+				return false
+			case ind.NumRows() < rp.MinIndexRowCount:
+				return false
+			default:
+				return true
+			}
+		})
+		// Put non-PK indexes first, then sort by decreasing size.
+		sort.Slice(indexes, func(i, j int) bool {
+			if indexes[i].IsPrimary() != indexes[j].IsPrimary() {
+				return indexes[j].IsPrimary()
+			}
+			return indexes[i].Size() > indexes[j].Size()
+		})
+		rp.relevantUnusedIndexes = indexes
+	}
+	return rp.relevantUnusedIndexes
+}
+
+func (rp *Report) NumRedundantIndexPairs() int       { return len(rp.RedundantIndexPairs) }
+func (rp *Report) FormatRedundantIndexPairs() string { return rp.redundantIndexTable().markdown() }
+
+func (rp *Report) redundantIndexTable() *table {
+	if rp.NumRedundantIndexPairs() == 0 {
+		return nil
+	}
+	sortIndexPairsBySize(rp.RedundantIndexPairs)
+	rows := make([][]interface{}, len(rp.RedundantIndexPairs))
+	hovers := make([]string, len(rp.RedundantIndexPairs))
+	for i, pair := range rp.RedundantIndexPairs {
+		ind1, ind2 := pair.Index, pair.Covering
+		rows[i] = []interface{}{
+			ind1.QualifiedTableName(),
+			ind1.Name(),
+			ind2.Name(),
+			ind1.Kind(),
+			int(ind1.Size().MiB()),
+			ind1.NumRows(),
+			ind1.NumScans(),
+			strings.Join(ind1.Attrs(), ", "),
+			strings.Join(ind2.Attrs(), ", "),
+		}
+		hovers[i] = ind1.Definition()
+	}
+	headings := []string{"Table", "Index1", "Index2", "T", "Size (MiB)", "Rows", "Scans", "Attrs1", "Attrs2"}
+	return &table{headings, rows, hovers}
+}
+
+func (rp *Report) NumInvalidIndexes() int       { return len(rp.InvalidIndexes) }
+func (rp *Report) FormatInvalidIndexes() string { return rp.invalidIndexTable().markdown() }
+
+func (rp *Report) invalidIndexTable() *table {
+	if rp.NumInvalidIndexes() == 0 {
+		return nil
+	}
+	sort.Slice(rp.InvalidIndexes, func(i, j int) bool {
+		return rp.InvalidIndexes[i].Size() > rp.InvalidIndexes[j].Size()
+	})
+	rows := make([][]interface{}, len(rp.InvalidIndexes))
+	for i, index := range rp.InvalidIndexes {
+		rows[i] = []interface{}{
+			index.QualifiedTableName(),
+			index.Name(),
+			index.Kind(),
+			index.State(),
+			int(index.Size().MiB()),
+			index.Definition(),
+			invalidIndexRemedy(index, rp.ServerVersion),
+		}
+	}
+	headings := []string{"Table", "Index", "T", "State", "Size (MiB)", "Definition", "Remedy"}
+	return &table{headings: headings, rows: rows}
+}
+
+func (rp *Report) NumOverIndexedTables() int       { return len(rp.OverIndexedTables) }
+func (rp *Report) FormatOverIndexedTables() string { return rp.overIndexedTable().markdown() }
+
+func (rp *Report) overIndexedTable() *table {
+	if rp.NumOverIndexedTables() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.OverIndexedTables))
+	for i, r := range rp.OverIndexedTables {
+		var reasons []string
+		if rp.IndexLimits.TooManyIndexes(r) {
+			reasons = append(reasons, "too many indexes")
+		}
+		if rp.IndexLimits.TooLarge(r) {
+			reasons = append(reasons, "indexes too large")
+		}
+		rows[i] = []interface{}{
+			r.Table.QualifiedName(),
+			r.NumIndexes,
+			int(r.IndexSize.MiB()),
+			int(r.Table.HeapSize().MiB()),
+			int(100 * r.IndexRatio()),
+			int(100 * r.Table.HotUpdateRatio()),
+			strings.Join(reasons, ", "),
+		}
+	}
+	headings := []string{"Table", "Indexes", "Index size (MiB)", "Table size (MiB)", "Index/table (%)", "HOT updates (%)", "Reason"}
+	return &table{headings: headings, rows: rows}
+}
+
+func (rp *Report) NumHotBlockers() int       { return len(rp.HotBlockers) }
+func (rp *Report) FormatHotBlockers() string { return rp.hotBlockerTable().markdown() }
+
+func (rp *Report) hotBlockerTable() *table {
+	if rp.NumHotBlockers() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.HotBlockers))
+	for i, b := range rp.HotBlockers {
+		var names []string
+		for _, ind := range b.Indexes {
+			names = append(names, ind.Name())
+		}
+		rows[i] = []interface{}{
+			b.Table.QualifiedName(),
+			b.Table.NumUpdates(),
+			int(100 * b.Table.HotUpdateRatio()),
+			b.Table.FillFactor(),
+			strings.Join(names, ", "),
+			strings.Join(b.Columns, ", "),
+			hotBlockerRemedy(b),
+		}
+	}
+	headings := []string{"Table", "Updates", "HOT updates (%)", "Fill factor", "Indexes", "Columns", "Suggestion"}
+	return &table{headings: headings, rows: rows}
+}
+
+// Suggests how to make more of a table's updates HOT: by dropping the indexes
+// on updated columns, if they are not needed, and by leaving room on each page
+// for new row versions.
+func hotBlockerRemedy(b check.HotBlocker) string {
+	var remedies []string
+	if len(b.Indexes) != 0 {
+		remedies = append(remedies, "drop the indexes on "+strings.Join(b.Columns, ", ")+" if unneeded")
+	}
+	if b.Table.FillFactor() >= 100 {
+		name := pgx.Identifier{b.Table.Namespace(), b.Table.Name()}.Sanitize()
+		remedies = append(remedies, "ALTER TABLE "+name+" SET (fillfactor = 90);")
+	}
+	if len(remedies) == 0 {
+		return "lower the fill factor further"
+	}
+	return strings.Join(remedies, " or ")
+}
+
+func (rp *Report) NumColumnFindings() int       { return len(rp.ColumnFindings) }
+func (rp *Report) FormatColumnFindings() string { return rp.columnFindingTable().markdown() }
+
+func (rp *Report) columnFindingTable() *table {
+	if rp.NumColumnFindings() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.ColumnFindings))
+	for i, f := range rp.ColumnFindings {
+		rows[i] = []interface{}{f.Column.QualifiedName(), f.Column.Type(), f.Rule, f.Advice}
+	}
+	headings := []string{"Column", "Type", "Rule", "Advice"}
+	return &table{headings: headings, rows: rows}
+}
+
+func (rp *Report) NumLowSelectivityIndexes() int { return len(rp.LowSelectivityIndexes) }
+func (rp *Report) FormatLowSelectivityIndexes() string {
+	return rp.lowSelectivityTable().markdown()
+}
+
+func (rp *Report) lowSelectivityTable() *table {
+	if rp.NumLowSelectivityIndexes() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.LowSelectivityIndexes))
+	hovers := make([]string, len(rp.LowSelectivityIndexes))
+	for i, l := range rp.LowSelectivityIndexes {
+		mostCommon := 0
+		if freqs := l.Stats.MostCommonFreqs(); len(freqs) > 0 {
+			mostCommon = int(100 * freqs[0])
+		}
+		rows[i] = []interface{}{
+			l.Index.QualifiedTableName(),
+			l.Index.Name(),
+			l.Column(),
+			int(l.Distinct),
+			mostCommon,
+			int(l.Index.Size().MiB()),
+			l.Index.NumScans(),
+			lowSelectivityRemedy(l),
+		}
+		hovers[i] = l.Index.Definition()
+	}
+	headings := []string{"Table", "Index", "Column", "Distinct", "Most common (%)", "Size (MiB)", "Scans", "Suggestion"}
+	return &table{headings, rows, hovers}
+}
+
+// Suggests what to do with a low-selectivity index: replace a single-column
+// index on a skewed column with a partial index that excludes the most common
+// value; otherwise, drop it.
+func lowSelectivityRemedy(l check.LowSelectivityIndex) string {
+	ind := l.Index
+	drop := dropIndex(ind)
+	if !l.Skewed() || len(ind.Attrs()) != 1 {
+		return drop
+	}
+	col := pgx.Identifier{l.Column()}.Sanitize()
+	return fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s (%s) WHERE %s <> %s; %s",
+		pgx.Identifier{ind.Namespace(), ind.TableName()}.Sanitize(), col, col,
+		quoteLiteral(l.Stats.MostCommonVals()[0]), drop)
+}
+
+// Quotes a string as a SQL literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// Suggests a statement that disposes of an unusable index on a server of the
+// given version. Unique indexes are rebuilt rather than dropped, since they
+// were presumably meant to enforce a constraint. Before Postgres 12, which
+// introduced REINDEX CONCURRENTLY, an index is rebuilt without blocking writes
+// by dropping it and creating it again.
+func invalidIndexRemedy(index *catalog.Index, version catalog.Version) string {
+	name := pgx.Identifier{index.Namespace(), index.Name()}.Sanitize()
+	switch {
+	case !index.IsLive():
+		return "none; index is being dropped"
+	case !index.IsUnique():
+		return "DROP INDEX CONCURRENTLY " + name + ";"
+	case version >= 120000:
+		return "REINDEX INDEX CONCURRENTLY " + name + ";"
+	case index.Definition() == "":
+		return "REINDEX INDEX " + name + ";" // blocks writes, but works on any version
+	}
+	create := strings.Replace(index.Definition(), " INDEX ", " INDEX CONCURRENTLY ", 1)
+	return "DROP INDEX CONCURRENTLY " + name + "; " + create + ";"
+}
+
+func sortIndexPairsBySize(a []check.RedundantPair) {
+	sort.Slice(a, func(i, j int) bool { return a[i].Index.Size() > a[j].Index.Size() })
+}
+
+// tmpl executes the given template text on data, writing the result to w.
+func tmpl(w io.Writer, text string, data interface{}) error {
+	t := template.New("top")
+	template.Must(t.Parse(text))
+	ew := &errWriter{w: w}
+	return ew.result(t.Execute(ew, data))
+}
+
+// An errWriter wraps a writer, recording whether a write error occurred.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Returns err, unless a write error occurred, which takes precedence.
+func (w *errWriter) result(err error) error {
+	if w.err != nil {
+		// I/O error. Ignore write on closed pipe.
+		if !strings.Contains(w.err.Error(), "pipe") {
+			return fmt.Errorf("writing report template: %v", w.err)
+		}
+	}
+	return err
+}
+
+// Sorts indexes lexicographically by name.
+type indexesByName []*catalog.Index
+
+func (a indexesByName) Len() int           { return len(a) }
+func (a indexesByName) Less(i, j int) bool { return a[i].Name() > a[j].Name() }
+func (a indexesByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+func indexesTable(indexes []*catalog.Index) *table {
+	rows := make([][]interface{}, len(indexes))
+	hovers := make([]string, len(indexes))
+	for i, index := range indexes {
+		rows[i] = []interface{}{
+			index.QualifiedTableName(),
+			indexName(index),
+			index.Kind(),
+			int(index.Size().MiB()),
+			index.NumRows(),
+			index.NumScans(),
+			strings.Join(index.Attrs(), ", "),
+		}
+		hovers[i] = index.Definition()
+	}
+	headings := []string{"Table", "Index", "T", "Size (MiB)", "Rows", "Scans", "Attrs"}
+	return &table{headings, rows, hovers}
+}
+
+// Returns the name of an index, noting how many partition indexes were rolled
+// up into it.
+func indexName(ind *catalog.Index) string {
+	if n := ind.NumPartitions(); n > 0 {
+		return fmt.Sprintf("%s (%d partitions)", ind.Name(), n)
+	}
+	return ind.Name()
+}
+
+// The markdown report is the connection info followed by the sections, which
+// the cluster report repeats for each database.
+const markdownReport = markdownHeader + markdownSections + "*Generated at {{ .Now }}*\n"
+
+const markdownHeader = `# pglint report for database "{{ .ConnConfig.Database }}"
+
+Connection info:
+
+* Host: {{ .ConnConfig.Host }}
+* Port: {{ .ConnConfig.Port }}
+* User: {{ .ConnConfig.User }}
+* Database: {{ .ConnConfig.Database }}
+* Server version: {{ .ServerVersion }}
+`
+
+const markdownSections = `{{ if .Interrupted }}
+**This report is incomplete.** pglint was interrupted before it could run the
+following checks, whose sections are therefore empty: {{ .FormatInterrupted }}.
+{{ end }}{{ if .Unsupported }}
+**Some checks were skipped.** The following checks are not supported on
+Postgres {{ .ServerVersion }}, so their sections are empty: {{ .FormatUnsupported }}.
+{{ end }}
+## Summary
+
+Dropping the indexes recommended below would free {{ .ReclaimableBytes.Human }}
+of disk space and avoid an estimated {{ .IndexWritesAvoided }} index tuple writes,
+based on the activity recorded since statistics were last reset. (Inserts and
+non-HOT updates add an entry to every index on a table; deletes leave an entry
+for vacuum to remove.) An index that appears in several sections is counted
+once in the total.
+
+{{ .FormatSummary }}
+
+## Duplicate Indexes
+
+Sets of duplicate indexes found: {{ .NumDuplicateIndexSets }}
+
+{{ .Describe "duplicate" }}
+
+{{ .FormatDuplicateIndexSets }}
+
+## Redundant Indexes
+
+Pairs of redundant indexes found: {{ .NumRedundantIndexPairs }}
+
+{{ .Describe "redundant" }}
+
+{{ .FormatRedundantIndexPairs }}
+
+## Invalid Indexes
+
+Invalid indexes found: {{ .NumInvalidIndexes }}
+
+{{ .Describe "invalid" }}
+
+{{ .FormatInvalidIndexes }}
+
+## Partition Index Gaps
+
+Partitions missing a sibling's index: {{ .NumPartitionGaps }}
+
+{{ .Describe "partitions" }}
+
+{{ .FormatPartitionGaps }}
+
+## Over-Indexed Tables
+
+Over-indexed tables found: {{ .NumOverIndexedTables }}
+
+{{ .Describe "overindexed" }}
+
+{{ .FormatOverIndexedTables }}
+
+## HOT Update Blockers
+
+Tables with few HOT updates found: {{ .NumHotBlockers }}
+
+{{ .Describe "hot" }}
+
+{{ .FormatHotBlockers }}
+
+## Low-Selectivity Indexes
+
+Low-selectivity indexes found: {{ .NumLowSelectivityIndexes }}
+
+{{ .Describe "lowselectivity" }}
+
+{{ .FormatLowSelectivityIndexes }}
+
+## Foreign Key Mismatches
+
+Mismatched foreign key columns found: {{ .NumForeignKeyMismatches }}
+
+{{ .Describe "fkmismatch" }}
+
+{{ .FormatForeignKeyMismatches }}
+
+## Column Types
+
+Questionable column types found: {{ .NumColumnFindings }}
+
+{{ .Describe "columns" }}
+
+{{ .FormatColumnFindings }}
+
+## NOT VALID Constraints
+
+Unvalidated constraints found: {{ .NumUnvalidatedConstraints }}
+
+{{ .Describe "notvalid" }}
+
+{{ .FormatUnvalidatedConstraints }}
+
+## Disabled Triggers
+
+Disabled triggers found: {{ .NumDisabledTriggers }}
+
+{{ .Describe "triggers" }}
+
+{{ .FormatDisabledTriggers }}
+{{ if not .InCluster }}` + markdownSettingsSection + `{{ end }}` + markdownSecuritySection + `
+## Row-Level Security
+
+Tenant tables with row-level security gaps: {{ .NumRLSGaps }}
+
+{{ .Describe "rls" }}
+
+{{ .FormatRLSGaps }}
+{{ if not .InCluster }}` + markdownReplicationSection + `{{ end }}
+## Unused Indexes
+
+Unused indexes found: {{ .NumUnusedIndexes }}
+
+{{ .Describe "unused" }}
+
+{{ .FormatUnusedIndexes }}
+` + markdownRuntimeSection
+
+// The sections about the cluster rather than the database, which the cluster
+// report shows once rather than in every database's sections; q.v.
+// Report.InCluster. The role findings of the Security section are about the
+// cluster too, while the rest are about the database.
+const markdownSettingsSection = `
+## Server Settings
+
+Risky or suboptimal settings found: {{ .NumSettingProblems }}
+
+{{ .Describe "settings" }}
+
+{{ .FormatSettingProblems }}
+`
+
+const markdownSecuritySection = `
+## Security
+
+Security findings: {{ .NumSecurityFindings }}
+
+{{ .Describe "security" }}
+
+{{ .FormatSecurityFindings }}
+`
+
+const markdownReplicationSection = `
+## Replication
+
+Replication slots with problems: {{ .NumSlotProblems }}
+
+{{ .Describe "slots" }}
+
+{{ .FormatSlotProblems }}
+
+Lagging replicas: {{ .NumLaggingReplicas }}
+
+{{ .Describe "replicas" }}
+
+{{ .FormatLaggingReplicas }}
+`
+
+const markdownRuntimeSection = `{{ if .Runtime }}
+## Runtime Health
+
+These findings describe the server's activity when pglint ran, in every
+database, and may be gone by the time you read this.
+
+### Long Transactions
+
+Transactions open longer than {{ .RuntimeOptions.MaxXactAge }}: {{ .NumLongTransactions }}
+
+{{ .Describe "longxacts" }}
+
+{{ .FormatLongTransactions }}
+
+### Idle in Transaction
+
+Sessions idle in transaction longer than {{ .RuntimeOptions.MaxIdleInXact }}: {{ .NumIdleInTransaction }}
+
+{{ .Describe "idleinxact" }}
+
+{{ .FormatIdleInTransaction }}
+
+### Lock Chains
+
+Sessions blocking others: {{ .NumLockChains }}
+
+{{ .Describe "lockchains" }}
+
+{{ .FormatLockChains }}
+
+### Orphaned Prepared Transactions
+
+Transactions prepared more than {{ .RuntimeOptions.MaxPreparedAge }} ago: {{ .NumOrphanedPrepared }}
+
+{{ .Describe "prepared" }}
+
+{{ .FormatOrphanedPrepared }}
+{{ end }}
+`
@@ -0,0 +1,558 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/jackc/pgx"
+)
+
+// Builds a report from the fixture shared with package check.
+func fixtureReport(t *testing.T) *Report {
+	t.Helper()
+	ctx := context.Background()
+	src, err := catalog.ReadSnapshot(filepath.Join("..", "check", "testdata", "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := &Report{
+		ConnConfig:             pgx.ConnConfig{Host: "localhost", Port: 5432, User: "u", Database: "fixture"},
+		ServerVersion:          130002,
+		GeneratedAt:            time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		UnusedIndexScansCutoff: 10,
+		MinIndexSize:           1 * catalog.MiB,
+		MinIndexRowCount:       10,
+		IndexLimits:            check.IndexLimits{MaxIndexes: 10, MaxIndexRatio: 1.5},
+		HotOptions: check.HotOptions{
+			MinHotRatio:    0.5,
+			MinUpdates:     1000,
+			UpdatedColumns: regexp.MustCompile(check.DefaultUpdatedColumns),
+		},
+		SelectivityOptions: check.SelectivityOptions{MaxDistinct: 10, MinRows: 1000},
+		ReplicationOptions: check.ReplicationOptions{
+			MaxRetainedWAL:    1 * catalog.GiB,
+			MaxCatalogXminAge: 10000000,
+			MaxLagBytes:       256 * catalog.MiB,
+			MaxLagTime:        time.Minute,
+		},
+	}
+	if rp.Tables, err = src.AllTables(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rp.AllIndexes, err = src.AllIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rp.DuplicateIndexSets, err = check.DuplicateIndexSets(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.UnusedIndexes, err = check.UnusedIndexes(ctx, src, rp.UnusedIndexScansCutoff); err != nil {
+		t.Fatal(err)
+	}
+	if rp.RedundantIndexPairs, err = check.RedundantIndexPairs(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.InvalidIndexes, err = check.InvalidIndexes(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.OverIndexedTables, err = check.OverIndexedTables(ctx, src, rp.IndexLimits); err != nil {
+		t.Fatal(err)
+	}
+	if rp.HotBlockers, err = check.HotBlockers(ctx, src, rp.HotOptions); err != nil {
+		t.Fatal(err)
+	}
+	if rp.LowSelectivityIndexes, err = check.LowSelectivityIndexes(ctx, src, rp.SelectivityOptions); err != nil {
+		t.Fatal(err)
+	}
+	if rp.ForeignKeyMismatches, err = check.ForeignKeyMismatches(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	columnOpts := check.ColumnOptions{MaxTinyVarchar: 4, LargeTableRows: 50000}
+	if rp.ColumnFindings, err = check.ColumnTypes(ctx, src, columnOpts); err != nil {
+		t.Fatal(err)
+	}
+	if rp.UnvalidatedConstraints, err = check.UnvalidatedConstraints(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.DisabledTriggers, err = check.DisabledTriggers(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.SettingProblems, err = check.SettingProblems(ctx, src, check.SettingsOptions{SSD: true}); err != nil {
+		t.Fatal(err)
+	}
+	if rp.SecurityFindings, err = check.SecurityAudit(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	rlsOpts := check.RLSOptions{Tables: map[string]bool{"users": true}, TenantColumns: regexp.MustCompile(`^user_id$`)}
+	if rp.RLSGaps, err = check.RowSecurityGaps(ctx, src, rlsOpts); err != nil {
+		t.Fatal(err)
+	}
+	if rp.ReplicationSlots, err = src.ReplicationSlots(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rp.SlotProblems = check.ReplicationSlotProblems(rp.ReplicationSlots, rp.ReplicationOptions)
+	if rp.LaggingReplicas, err = check.LaggingReplicas(ctx, src, rp.ReplicationOptions); err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+func TestReclaimableBytes(t *testing.T) {
+	// users_email_idx (2 MiB) is a less-scanned duplicate, redundant and
+	// unused, but is counted once; users_email_idx2 (2 MiB) is redundant;
+	// orders_pkey (1.7 MiB) is unused; orders_status_idx (1 MiB) is invalid.
+	want := catalog.Bytes(2*catalog.MiB + 2*catalog.MiB + 1802240 + 1*catalog.MiB)
+	if got := fixtureReport(t).ReclaimableBytes(); got != want {
+		t.Errorf("got %d bytes, want %d", got, want)
+	}
+}
+
+func TestSectionSavings(t *testing.T) {
+	bySection, total := fixtureReport(t).sectionSavings()
+	// users has 5600 index writes per index, orders 88000.
+	tests := []struct {
+		section string
+		got     savings
+		want    savings
+	}{
+		{"duplicate", bySection["duplicate"], savings{1, 2 * catalog.MiB, 5600}},
+		{"redundant", bySection["redundant"], savings{2, 4 * catalog.MiB, 11200}},
+		{"unused", bySection["unused"], savings{2, 2*catalog.MiB + 1802240, 93600}},
+		{"invalid", bySection["invalid"], savings{1, 1 * catalog.MiB, 88000}},
+		{"total", total, savings{4, 5*catalog.MiB + 1802240, 187200}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.section, tt.got, tt.want)
+		}
+	}
+}
+
+func TestInvalidIndexRemedy(t *testing.T) {
+	var unique catalog.Index
+	err := json.Unmarshal([]byte(`{"name": "users_email_key", "namespace": "public", "is_unique": true,
+		"is_live": true, "definition": "CREATE UNIQUE INDEX users_email_key ON public.users USING btree (email)"}`), &unique)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		version catalog.Version
+		want    string
+	}{
+		{120000, `REINDEX INDEX CONCURRENTLY "public"."users_email_key";`},
+		{110005, `DROP INDEX CONCURRENTLY "public"."users_email_key"; ` +
+			`CREATE UNIQUE INDEX CONCURRENTLY users_email_key ON public.users USING btree (email);`},
+		{90624, `DROP INDEX CONCURRENTLY "public"."users_email_key"; ` +
+			`CREATE UNIQUE INDEX CONCURRENTLY users_email_key ON public.users USING btree (email);`},
+	}
+	for _, tt := range tests {
+		if got := invalidIndexRemedy(&unique, tt.version); got != tt.want {
+			t.Errorf("invalidIndexRemedy on %s = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestFormatOverIndexedTables(t *testing.T) {
+	// Collapse the padding for easier comparison.
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatOverIndexedTables()), " ")
+	want := "| users | 6 | 9 | 4 | 231 | 75 | indexes too large |"
+	if !strings.Contains(got, want) {
+		t.Errorf("over-indexed tables %q do not contain %q", got, want)
+	}
+	if strings.Contains(got, "orders") {
+		t.Errorf("over-indexed tables %q should not contain orders", got)
+	}
+}
+
+func TestFormatHotBlockers(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatHotBlockers()), " ")
+	want := "| orders | 10000 | 20 | 100 | orders_user_id_created_at_idx, orders_status_idx | deleted_at, status |" +
+		` drop the indexes on deleted_at, status if unneeded or ALTER TABLE "public"."orders" SET (fillfactor = 90); |`
+	if !strings.Contains(got, want) {
+		t.Errorf("HOT blockers %q do not contain %q", got, want)
+	}
+}
+
+func TestFormatLowSelectivityIndexes(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatLowSelectivityIndexes()), " ")
+	want := "| users | users_active_idx | active | 2 | 97 | 0 | 500 |" +
+		` CREATE INDEX CONCURRENTLY ON "public"."users" ("active") WHERE "active" <> 't';` +
+		` DROP INDEX CONCURRENTLY "public"."users_active_idx"; |`
+	if !strings.Contains(got, want) {
+		t.Errorf("low-selectivity indexes %q do not contain %q", got, want)
+	}
+}
+
+func TestFormatForeignKeyMismatches(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatForeignKeyMismatches()), " ")
+	for _, want := range []string{
+		`| orders_user_email_fkey | orders | email | character varying(100) collate "C" | users | email |` +
+			` character varying(100) collate "default" not null | collation, nullability |`,
+		"| orders_user_id_fkey | orders | user_id | integer not null | users | id | bigint not null | type |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("foreign key mismatches %q do not contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatColumnFindings(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatColumnFindings()), " ")
+	want := "| users.settings | json | json | use jsonb,"
+	if !strings.Contains(got, want) {
+		t.Errorf("column findings %q do not contain %q", got, want)
+	}
+}
+
+func TestFormatIntegrityGaps(t *testing.T) {
+	rp := fixtureReport(t)
+	tests := []struct {
+		section, got, want string
+	}{
+		{"constraints", rp.FormatUnvalidatedConstraints(),
+			`ALTER TABLE "public"."orders" VALIDATE CONSTRAINT "orders_total_check";`},
+		{"triggers", rp.FormatDisabledTriggers(),
+			`| orders | orders_audit | audit_row | | ALTER TABLE "public"."orders" ENABLE TRIGGER "orders_audit"; |`},
+		{"triggers", rp.FormatDisabledTriggers(),
+			`| orders | RI_ConstraintTrigger_c_3001 | RI_FKey_check_ins | yes |`},
+	}
+	for _, tt := range tests {
+		got := strings.Join(strings.Fields(tt.got), " ")
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: %q does not contain %q", tt.section, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSettingProblems(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatSettingProblems()), " ")
+	for _, want := range []string{
+		"| full_page_writes | off | configuration file | on |",
+		"ALTER SYSTEM SET full_page_writes = 'on'; |",
+		"| random_page_cost | 4 | default | 1.1 |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatSecurityFindings(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatSecurityFindings()), " ")
+	for _, want := range []string{
+		`| superuser | role admin | bypasses all permission checks | ALTER ROLE "admin" NOSUPERUSER; |`,
+		`| no expiry | role ops | password never expires | ALTER ROLE "ops" VALID UNTIL '2021-05-30'; |`,
+		`| md5 | role app | password stored as an md5 hash; set it again in psql with ` +
+			`SET password_encryption = 'scram-sha-256' and \password "app" | |`,
+		`| public create | schema public | PUBLIC may create objects | REVOKE CREATE ON SCHEMA "public" FROM PUBLIC; |`,
+		`| public grant | table users | PUBLIC has SELECT, UPDATE | REVOKE ALL ON TABLE "public"."users" FROM PUBLIC; |`,
+		`| security definer | function touch_user(user_id bigint) | SECURITY DEFINER without a fixed search_path | ` +
+			`ALTER ROUTINE "public"."touch_user"(user_id bigint) SET search_path = "public", pg_temp; |`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatSecurityFindingsOldServer(t *testing.T) {
+	rp := fixtureReport(t)
+	rp.ServerVersion = 90624
+	got := strings.Join(strings.Fields(rp.FormatSecurityFindings()), " ")
+	for _, want := range []string{
+		`| md5 | role app | password stored as an md5 hash; SCRAM requires Postgres 10 or later | |`,
+		// Procedures, and ALTER ROUTINE, are new in Postgres 11.
+		`ALTER FUNCTION "public"."touch_user"(user_id bigint) SET search_path = "public", pg_temp; |`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatPartitionGaps(t *testing.T) {
+	ctx := context.Background()
+	src, err := catalog.ReadSnapshot(filepath.Join("..", "check", "testdata", "partitions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := &Report{}
+	if rp.Tables, err = src.AllTables(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rp.PartitionGaps, err = check.PartitionIndexGaps(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if rp.UnusedIndexes, err = check.UnusedIndexes(ctx, src, 10); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(strings.Fields(rp.FormatPartitionGaps()), " ")
+	want := `| events_2025 | events | events_2026 | events_2026_user_id_idx | user_id | ` +
+		`CREATE INDEX CONCURRENTLY ON "public"."events_2025" (user_id); |`
+	if !strings.Contains(got, want) {
+		t.Errorf("%q does not contain %q", got, want)
+	}
+	got = strings.Join(strings.Fields(rp.FormatUnusedIndexes()), " ")
+	want = "| events | events_created_at_idx1 (2 partitions) | N | 2 | 110000 | 0 | created_at |"
+	if !strings.Contains(got, want) {
+		t.Errorf("%q does not contain %q", got, want)
+	}
+	// Writes to either partition maintain the partitioned index.
+	if s := rp.savingsOf(rp.UnusedIndexes); s.Writes != 110000 {
+		t.Errorf("got %d index writes avoided, want 110000", s.Writes)
+	}
+}
+
+func TestTriageItems(t *testing.T) {
+	var got []string
+	for _, it := range fixtureReport(t).TriageItems() {
+		got = append(got, it.Check+" "+it.Index.Name()+": "+it.Reason)
+	}
+	for _, want := range []string{
+		"duplicate users_email_idx: duplicate of users_email_idx2",
+		"duplicate users_email_idx2: duplicate of users_email_idx; the most scanned of its set",
+		"unused orders_pkey: scanned 5 times",
+	} {
+		found := false
+		for _, s := range got {
+			found = found || s == want
+		}
+		if !found {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatRLSGaps(t *testing.T) {
+	got := strings.Join(strings.Fields(fixtureReport(t).FormatRLSGaps()), " ")
+	for _, want := range []string{
+		`| users | not forced, no INSERT policy, no DELETE policy | ALTER TABLE "public"."users" FORCE ROW LEVEL SECURITY; |`,
+		`| orders | RLS disabled, no SELECT policy, no INSERT policy, no UPDATE policy, no DELETE policy | ` +
+			`ALTER TABLE "public"."orders" ENABLE ROW LEVEL SECURITY; ALTER TABLE "public"."orders" FORCE ROW LEVEL SECURITY; |`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestDescribeMissingRLSTables(t *testing.T) {
+	rp := fixtureReport(t)
+	if desc, err := rp.Describe("rls"); err != nil || strings.Contains(desc, "not found") {
+		t.Errorf("got description %q, %v; want no missing tables", desc, err)
+	}
+	rp.MissingRLSTables = []string{"accounts", "audit.log"}
+	want := "<p><strong>Tables not found:</strong> accounts, audit.log."
+	if got := string(rp.describeHTML("rls")); !strings.Contains(got, want) {
+		t.Errorf("%q does not contain %q", got, want)
+	}
+}
+
+func TestFormatReplication(t *testing.T) {
+	rp := fixtureReport(t)
+	tests := []struct {
+		section, got, want string
+	}{
+		{"slots", rp.FormatSlotProblems(),
+			"| standby2 | physical | | no | 50.0 GiB | 0 | inactive, retains WAL | SELECT pg_drop_replication_slot('standby2'); |"},
+		{"slots", rp.FormatSlotProblems(),
+			"| search_sync | logical | shop | yes | 256.0 MiB | 25000000 | holds back vacuum | |"},
+		{"replicas", rp.FormatLaggingReplicas(),
+			"| reporting | 10.0.0.12 | streaming | async | 1.0 GiB | 7m0s |"},
+	}
+	for _, tt := range tests {
+		got := strings.Join(strings.Fields(tt.got), " ")
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: %q does not contain %q", tt.section, got, tt.want)
+		}
+	}
+}
+
+func TestRuntimeHealth(t *testing.T) {
+	rp := fixtureReport(t)
+	var buf bytes.Buffer
+	if err := rp.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Runtime Health") {
+		t.Error("report contains a runtime section without -runtime")
+	}
+
+	src, err := catalog.ReadSnapshot(filepath.Join("..", "check", "testdata", "indexes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp.RuntimeOptions = check.RuntimeOptions{MaxXactAge: 5 * time.Minute, MaxIdleInXact: time.Minute, MaxPreparedAge: 5 * time.Minute}
+	if rp.Runtime, err = check.CheckRuntime(context.Background(), src, rp.RuntimeOptions); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := rp.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{
+		"Transactions open longer than 5m0s: 2",
+		"| 6004 | report | shop | psql | active | 2h0m0s | SELECT count(*) FROM orders JOIN users ON users.id = orde... |",
+		"| 6001 | app | shop | api | 14m0s | 15m0s | UPDATE users SET last_seen = now() WHERE id = $1 | SELECT pg_terminate_backend(6001); |",
+		"| 6001 | app | idle in transaction | 15m0s | 2 | 6002, 6003 | users |",
+		"| payment-17 | shop | app | 72h0m0s | ROLLBACK PREPARED 'payment-17'; |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := fixtureReport(t).GenerateHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		`<details id="duplicate" open>`,
+		`<td class="num" data-sort="2">2</td>`,
+		`users_email_name_idx`,
+		`<tfoot><tr><td>Total</td>`,
+		"<li>Is either non-unique or is a primary key.</li>",
+		`<td class="num" data-sort="53687091200">50.0 GiB</td>`, // sorts by bytes, not by text
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	md := "A <b> & _this_ one.\nSee snake_case_name.\n\n* **Bold**\n* Two"
+	want := "<p>A &lt;b&gt; &amp; <em>this</em> one.\nSee snake_case_name.</p>\n" +
+		"<ul>\n<li><strong>Bold</strong></li>\n<li>Two</li>\n</ul>\n"
+	if got := string(markdownToHTML(md)); got != want {
+		t.Errorf("markdownToHTML:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestGenerateInterrupted(t *testing.T) {
+	rp := fixtureReport(t)
+	var buf bytes.Buffer
+	if err := rp.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "incomplete") {
+		t.Error("complete report is marked incomplete")
+	}
+	rp.Interrupted = []string{"unused indexes", "security audit"}
+	buf.Reset()
+	if err := rp.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(strings.Fields(buf.String()), " ")
+	want := "**This report is incomplete.** pglint was interrupted before it could run the following checks, whose sections are therefore empty: unused indexes, security audit."
+	if !strings.Contains(got, want) {
+		t.Errorf("report does not contain %q", want)
+	}
+	buf.Reset()
+	if err := rp.GenerateHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<p class="incomplete">`) {
+		t.Error("HTML report is not marked incomplete")
+	}
+}
+
+func TestGenerateMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := fixtureReport(t).GenerateMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	metrics := buf.String()
+	for _, want := range []string{
+		"# TYPE pglint_duplicate_index_sets gauge\n",
+		`pglint_duplicate_index_sets{database="fixture"} 1` + "\n",
+		`pglint_invalid_indexes{database="fixture"} 1` + "\n",
+		`pglint_over_indexed_tables{database="fixture"} 1` + "\n",
+		`pglint_index_size_bytes{database="fixture",schema="public",table="users",index="users_email_idx"} 2097152` + "\n",
+		`pglint_index_scans_total{database="fixture",schema="public",table="orders",index="orders_pkey"} 5` + "\n",
+		`pglint_replication_slot_problems{database="fixture"} 2` + "\n",
+		`pglint_replication_slot_retained_bytes{database="fixture",slot="standby2"} 53687091200` + "\n",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics do not contain %q", want)
+		}
+	}
+}
+
+func TestQuoteLabel(t *testing.T) {
+	if got, want := quoteLabel("a\"b\\c\nd"), `"a\"b\\c\nd"`; got != want {
+		t.Errorf("quoteLabel = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateCluster(t *testing.T) {
+	app, audit := fixtureReport(t), fixtureReport(t)
+	audit.ConnConfig.Database = "audit"
+	audit.Interrupted = []string{"unused indexes"}
+	cr := &ClusterReport{
+		ConnConfig:    app.ConnConfig,
+		ServerVersion: app.ServerVersion,
+		Failures:      []DatabaseFailure{{Database: "locked", Err: errors.New("permission denied for database locked")}},
+		Unchecked:     []string{"reports"},
+	}
+	before := app.numFindings()
+	cr.AddDatabase(app)
+	cr.AddDatabase(audit)
+	var buf bytes.Buffer
+	if err := cr.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	fields := strings.Join(strings.Fields(got), " ")
+
+	// The findings about the cluster are counted once, not in each database.
+	c, n := cr.Cluster.numFindings(), app.numFindings()
+	if c == 0 || c+n != before {
+		t.Errorf("got %d cluster and %d database findings, want them to sum to %d", c, n, before)
+	}
+	for _, want := range []string{
+		"# pglint report for cluster localhost:5432 ",
+		"**This report is incomplete.** pglint was interrupted, so some checks did not run in these databases: audit; and these databases were not checked at all: reports.",
+		"Databases checked: 2 of 4 ",
+		fmt.Sprintf("| (cluster) | %d |", c),
+		fmt.Sprintf("| fixture | %d |", n),
+		fmt.Sprintf("| audit | %d |", n),
+		fmt.Sprintf("| Total | %d |", c+2*n),
+		"| locked | permission denied for database locked |",
+		"## Cluster ### Server Settings",
+		"## Database \"fixture\" ### Summary",
+		"## Database \"audit\" **This report is incomplete.** pglint was interrupted before it could run the following checks, whose sections are therefore empty: unused indexes. ### Summary",
+		" ### Duplicate Indexes ",
+	} {
+		if !strings.Contains(fields, want) {
+			t.Errorf("cluster report does not contain %q", want)
+		}
+	}
+	for heading, count := range map[string]int{"### Server Settings": 1, "### Replication": 1, "### Security": 3} {
+		if got := strings.Count(got, "\n"+heading+"\n"); got != count {
+			t.Errorf("cluster report has %d %q sections, want %d", got, heading, count)
+		}
+	}
+	if strings.Contains(got, "| reports |") {
+		t.Error("cluster report lists a database skipped after the interrupt as a failure")
+	}
+	if strings.Count(got, "*Generated at") != 1 {
+		t.Error("cluster report does not have exactly one footer")
+	}
+	if strings.Contains(got, "\n## Duplicate Indexes\n") {
+		t.Error("cluster report has undemoted database sections")
+	}
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/jackc/pgx"
+)
+
+func (rp *Report) NumRLSGaps() int       { return len(rp.RLSGaps) }
+func (rp *Report) FormatRLSGaps() string { return rp.rlsTable().markdown() }
+
+// FormatMissingRLSTables lists the tables named with -rlstables that do not
+// exist, e.g. "accounts, audit.log".
+func (rp *Report) FormatMissingRLSTables() string { return strings.Join(rp.MissingRLSTables, ", ") }
+
+func (rp *Report) rlsTable() *table {
+	if rp.NumRLSGaps() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.RLSGaps))
+	for i, g := range rp.RLSGaps {
+		t := g.Table
+		name := pgx.Identifier{t.Namespace(), t.Name()}.Sanitize()
+		var statements []string
+		if !t.RowSecurity() {
+			statements = append(statements, "ALTER TABLE "+name+" ENABLE ROW LEVEL SECURITY;")
+		}
+		if !t.ForceRowSecurity() {
+			statements = append(statements, "ALTER TABLE "+name+" FORCE ROW LEVEL SECURITY;")
+		}
+		rows[i] = []interface{}{t.QualifiedName(), strings.Join(g.Problems, ", "), strings.Join(statements, " ")}
+	}
+	headings := []string{"Table", "Problems", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (rp *Report) NumLongTransactions() int {
+	if rp.Runtime == nil {
+		return 0
+	}
+	return len(rp.Runtime.LongTransactions)
+}
+
+func (rp *Report) NumIdleInTransaction() int {
+	if rp.Runtime == nil {
+		return 0
+	}
+	return len(rp.Runtime.IdleInTransaction)
+}
+
+func (rp *Report) NumLockChains() int {
+	if rp.Runtime == nil {
+		return 0
+	}
+	return len(rp.Runtime.LockChains)
+}
+
+func (rp *Report) NumOrphanedPrepared() int {
+	if rp.Runtime == nil {
+		return 0
+	}
+	return len(rp.Runtime.OrphanedPrepared)
+}
+
+func (rp *Report) FormatLongTransactions() string  { return rp.longTransactionTable().markdown() }
+func (rp *Report) FormatIdleInTransaction() string { return rp.idleInTransactionTable().markdown() }
+func (rp *Report) FormatLockChains() string        { return rp.lockChainTable().markdown() }
+func (rp *Report) FormatOrphanedPrepared() string  { return rp.orphanedPreparedTable().markdown() }
+
+func (rp *Report) longTransactionTable() *table {
+	if rp.NumLongTransactions() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.Runtime.LongTransactions))
+	hovers := make([]string, len(rp.Runtime.LongTransactions))
+	for i, s := range rp.Runtime.LongTransactions {
+		rows[i] = []interface{}{
+			s.PID(),
+			s.User(),
+			s.Database(),
+			s.Application(),
+			s.State(),
+			fmtDuration(s.XactAge()),
+			abbreviateQuery(s.Query()),
+		}
+		hovers[i] = s.Query()
+	}
+	headings := []string{"PID", "User", "Database", "Application", "State", "Transaction age", "Query"}
+	return &table{headings, rows, hovers}
+}
+
+func (rp *Report) idleInTransactionTable() *table {
+	if rp.NumIdleInTransaction() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.Runtime.IdleInTransaction))
+	hovers := make([]string, len(rp.Runtime.IdleInTransaction))
+	for i, s := range rp.Runtime.IdleInTransaction {
+		rows[i] = []interface{}{
+			s.PID(),
+			s.User(),
+			s.Database(),
+			s.Application(),
+			fmtDuration(s.StateAge()),
+			fmtDuration(s.XactAge()),
+			abbreviateQuery(s.Query()),
+			fmt.Sprintf("SELECT pg_terminate_backend(%d);", s.PID()),
+		}
+		hovers[i] = s.Query()
+	}
+	headings := []string{"PID", "User", "Database", "Application", "Idle for", "Transaction age", "Last query", "Statement"}
+	return &table{headings, rows, hovers}
+}
+
+func (rp *Report) lockChainTable() *table {
+	if rp.NumLockChains() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.Runtime.LockChains))
+	hovers := make([]string, len(rp.Runtime.LockChains))
+	for i, c := range rp.Runtime.LockChains {
+		var pids, relations []string
+		seen := make(map[string]bool)
+		for _, w := range c.Waiters {
+			pids = append(pids, fmt.Sprint(w.PID()))
+			if r := w.WaitingOn(); r != "" && !seen[r] {
+				seen[r] = true
+				relations = append(relations, r)
+			}
+		}
+		rows[i] = []interface{}{
+			c.Blocker.PID(),
+			c.Blocker.User(),
+			c.Blocker.State(),
+			fmtDuration(c.Blocker.XactAge()),
+			len(c.Waiters),
+			strings.Join(pids, ", "),
+			strings.Join(relations, ", "),
+			abbreviateQuery(c.Blocker.Query()),
+		}
+		hovers[i] = c.Blocker.Query()
+	}
+	headings := []string{"Blocker PID", "User", "State", "Transaction age", "Waiters", "Waiting PIDs", "Relations", "Blocker query"}
+	return &table{headings, rows, hovers}
+}
+
+func (rp *Report) orphanedPreparedTable() *table {
+	if rp.NumOrphanedPrepared() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.Runtime.OrphanedPrepared))
+	for i, p := range rp.Runtime.OrphanedPrepared {
+		rows[i] = []interface{}{
+			p.GID(),
+			p.Database(),
+			p.Owner(),
+			fmtDuration(p.Age()),
+			"ROLLBACK PREPARED " + quoteLiteral(p.GID()) + ";",
+		}
+	}
+	headings := []string{"GID", "Database", "Owner", "Age", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
+
+// Formats a duration to the nearest second, e.g. "1h2m3s".
+func fmtDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// Collapses the whitespace in a query, including newlines, and truncates it to
+// fit in a table cell. The full text is shown when hovering in the HTML report.
+func abbreviateQuery(q string) string {
+	const maxLen = 60
+	q = strings.Join(strings.Fields(q), " ")
+	if r := []rune(q); len(r) > maxLen {
+		return string(r[:maxLen-3]) + "..."
+	}
+	return q
+}
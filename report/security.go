@@ -0,0 +1,89 @@
+package report
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dcowgill/pglint/check"
+	"github.com/jackc/pgx"
+)
+
+// How long from now passwords without an expiry date should remain valid.
+const passwordLifetime = 90 * 24 * time.Hour
+
+func (rp *Report) NumSecurityFindings() int       { return len(rp.SecurityFindings) }
+func (rp *Report) FormatSecurityFindings() string { return rp.securityTable().markdown() }
+
+func (rp *Report) securityTable() *table {
+	if rp.NumSecurityFindings() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.SecurityFindings))
+	for i, f := range rp.SecurityFindings {
+		object, detail, statement := rp.describeSecurityFinding(f)
+		rows[i] = []interface{}{f.Kind, object, detail, statement}
+	}
+	headings := []string{"Finding", "Object", "Detail", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
+
+// Names the object of a security finding, describes the problem, and
+// suggests a statement that fixes it on the report's server.
+func (rp *Report) describeSecurityFinding(f check.SecurityFinding) (object, detail, statement string) {
+	switch {
+	case f.Role != nil:
+		name := pgx.Identifier{f.Role.Name()}.Sanitize()
+		object = "role " + f.Role.Name()
+		switch f.Kind {
+		case "superuser":
+			return object, "bypasses all permission checks", "ALTER ROLE " + name + " NOSUPERUSER;"
+		case "createrole":
+			return object, "can create roles and grant membership in any role", "ALTER ROLE " + name + " NOCREATEROLE;"
+		case "bypassrls":
+			return object, "bypasses row-level security", "ALTER ROLE " + name + " NOBYPASSRLS;"
+		case "no expiry":
+			expiry := rp.generatedAt().Add(passwordLifetime).Format("2006-01-02")
+			return object, "password never expires", "ALTER ROLE " + name + " VALID UNTIL '" + expiry + "';"
+		case "md5":
+			// Only the user knows the password, so there is no statement to
+			// run; psql's \password, which is not SQL, sets it again.
+			detail = "password stored as an md5 hash"
+			if f.Role.PasswordType() == "plain" {
+				detail = "password stored in plain text"
+			}
+			switch {
+			case rp.ServerVersion == 0 || rp.ServerVersion >= 100000:
+				detail += `; set it again in psql with SET password_encryption = 'scram-sha-256' and \password ` + name
+			case f.Role.PasswordType() == "plain":
+				// Before Postgres 10, password_encryption is a boolean.
+				detail += `; set it again in psql with SET password_encryption = on and \password ` + name
+			default:
+				detail += "; SCRAM requires Postgres 10 or later"
+			}
+			return object, detail, ""
+		}
+	case f.Grant != nil:
+		g := f.Grant
+		if g.ObjectType() == "schema" {
+			name := pgx.Identifier{g.Name()}.Sanitize()
+			return "schema " + g.Name(), "PUBLIC may create objects",
+				"REVOKE CREATE ON SCHEMA " + name + " FROM PUBLIC;"
+		}
+		name := pgx.Identifier{g.Namespace(), g.Name()}.Sanitize()
+		return "table " + g.QualifiedName(),
+			"PUBLIC has " + strings.Join(g.Privileges(), ", "),
+			"REVOKE ALL ON TABLE " + name + " FROM PUBLIC;"
+	case f.Function != nil:
+		fn := f.Function
+		name := pgx.Identifier{fn.Namespace(), fn.Name()}.Sanitize() + "(" + fn.Args() + ")"
+		// From Postgres 11, the function may be a procedure, which ALTER
+		// ROUTINE alters too.
+		alter := "ALTER ROUTINE "
+		if rp.ServerVersion != 0 && rp.ServerVersion < 110000 {
+			alter = "ALTER FUNCTION "
+		}
+		return "function " + fn.QualifiedName(), "SECURITY DEFINER without a fixed search_path",
+			alter + name + " SET search_path = " + pgx.Identifier{fn.Namespace()}.Sanitize() + ", pg_temp;"
+	}
+	return object, f.Kind, ""
+}
@@ -0,0 +1,24 @@
+package report
+
+func (rp *Report) NumSettingProblems() int       { return len(rp.SettingProblems) }
+func (rp *Report) FormatSettingProblems() string { return rp.settingTable().markdown() }
+
+func (rp *Report) settingTable() *table {
+	if rp.NumSettingProblems() == 0 {
+		return nil
+	}
+	rows := make([][]interface{}, len(rp.SettingProblems))
+	for i, f := range rp.SettingProblems {
+		s := f.Setting
+		rows[i] = []interface{}{
+			s.Name(),
+			s.Display(),
+			s.Source(),
+			f.Suggested,
+			f.Advice,
+			"ALTER SYSTEM SET " + s.Name() + " = " + quoteLiteral(f.Suggested) + ";",
+		}
+	}
+	headings := []string{"Setting", "Value", "Source", "Suggested", "Reason", "Statement"}
+	return &table{headings: headings, rows: rows}
+}
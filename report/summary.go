@@ -0,0 +1,112 @@
+package report
+
+import (
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// savings summarizes the payoff of dropping a set of indexes.
+type savings struct {
+	Indexes int           // number of indexes dropped
+	Bytes   catalog.Bytes // disk space freed
+	Writes  int           // estimated index tuple writes avoided
+}
+
+// Computes the savings of dropping the indexes, counting each index once.
+func (rp *Report) savingsOf(indexes []*catalog.Index) savings {
+	var (
+		s      savings
+		seen   = make(map[pgtype.OID]bool)
+		tables = catalog.TablesByOID(rp.Tables)
+	)
+	// A partitioned index is maintained on writes to any of the partitions.
+	for _, t := range catalog.RollUpTables(rp.Tables) {
+		tables[t.OID()] = t
+	}
+	for _, ind := range indexes {
+		if seen[ind.OID()] {
+			continue
+		}
+		seen[ind.OID()] = true
+		s.Indexes++
+		s.Bytes += ind.Size()
+		if t, ok := tables[ind.TableOID()]; ok && ind.IsLive() && ind.IsReady() {
+			s.Writes += t.IndexWrites()
+		}
+	}
+	return s
+}
+
+// Returns the indexes that each section of the report recommends dropping:
+// all but the most scanned index of each set of duplicates, redundant
+// indexes, unused indexes, and invalid indexes.
+func (rp *Report) dropCandidates() (duplicate, redundant, unused, invalid []*catalog.Index) {
+	for _, set := range rp.DuplicateIndexSets {
+		keep := set[0]
+		for _, ind := range set[1:] {
+			if ind.NumScans() > keep.NumScans() {
+				keep = ind
+			}
+		}
+		for _, ind := range set {
+			if ind != keep {
+				duplicate = append(duplicate, ind)
+			}
+		}
+	}
+	for _, pair := range rp.RedundantIndexPairs {
+		redundant = append(redundant, pair.Index)
+	}
+	return duplicate, redundant, rp.getRelevantUnusedIndexes(), rp.InvalidIndexes
+}
+
+// Returns the savings of each section's drop candidates, keyed by the section
+// IDs used in the HTML report, along with the total savings of dropping all
+// of them.
+func (rp *Report) sectionSavings() (map[string]savings, savings) {
+	duplicate, redundant, unused, invalid := rp.dropCandidates()
+	var all []*catalog.Index
+	for _, a := range [][]*catalog.Index{duplicate, redundant, unused, invalid} {
+		all = append(all, a...)
+	}
+	return map[string]savings{
+		"duplicate": rp.savingsOf(duplicate),
+		"redundant": rp.savingsOf(redundant),
+		"unused":    rp.savingsOf(unused),
+		"invalid":   rp.savingsOf(invalid),
+	}, rp.savingsOf(all)
+}
+
+// ReclaimableBytes reports the disk space that would be freed by dropping the
+// indexes the report recommends dropping. Each index is counted once, however
+// many sections it appears in.
+func (rp *Report) ReclaimableBytes() catalog.Bytes {
+	_, total := rp.sectionSavings()
+	return total.Bytes
+}
+
+// IndexWritesAvoided estimates the index tuple writes that would have been
+// avoided, since statistics were last reset, had the indexes the report
+// recommends dropping not existed; q.v. catalog.Table.IndexWrites.
+func (rp *Report) IndexWritesAvoided() string {
+	_, total := rp.sectionSavings()
+	return fmtInt(total.Writes)
+}
+
+func (rp *Report) FormatSummary() string { return rp.summaryTable().markdown() }
+
+func (rp *Report) summaryTable() *table {
+	bySection, total := rp.sectionSavings()
+	row := func(name string, s savings) []interface{} {
+		return []interface{}{name, s.Indexes, s.Bytes, s.Writes}
+	}
+	rows := [][]interface{}{
+		row("Duplicate indexes", bySection["duplicate"]),
+		row("Redundant indexes", bySection["redundant"]),
+		row("Invalid indexes", bySection["invalid"]),
+		row("Unused indexes", bySection["unused"]),
+		row("Total", total),
+	}
+	headings := []string{"Section", "Indexes to drop", "Reclaimable", "Index writes avoided"}
+	return &table{headings: headings, rows: rows}
+}
@@ -0,0 +1,17 @@
+package report
+
+// A table is the tabular content of a report section, independent of the
+// output format.
+type table struct {
+	headings []string
+	rows     [][]interface{} // cells, formatted as by pprintTable
+	hovers   []string        // optional; detail shown when hovering over a row
+}
+
+// Formats the table as markdown. A nil table formats as an empty string.
+func (t *table) markdown() string {
+	if t == nil {
+		return ""
+	}
+	return pprintTableString(t.headings, t.rows, "")
+}
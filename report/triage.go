@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx"
+)
+
+// A TriageItem is a finding about an index, to be reviewed interactively and
+// either acted upon or suppressed.
+type TriageItem struct {
+	Check  string // ID of the report section, e.g. "unused"; q.v. check.Suppression
+	Index  *catalog.Index
+	Reason string // why the index was reported
+	Remedy string // a statement that fixes the finding; empty if there is none
+}
+
+// TriageItems returns the findings of the index sections of the report, in
+// the order of the report: duplicate, redundant, invalid, low-selectivity and
+// unused indexes. Every index in a set of duplicates is included, so that the
+// reviewer can choose which to keep.
+func (rp *Report) TriageItems() []TriageItem {
+	var items []TriageItem
+	duplicates, _, _, _ := rp.dropCandidates()
+	for _, set := range rp.DuplicateIndexSets {
+		for _, ind := range set {
+			var others []string
+			for _, other := range set {
+				if other != ind {
+					others = append(others, other.Name())
+				}
+			}
+			reason := "duplicate of " + strings.Join(others, ", ")
+			if !containsIndex(duplicates, ind) {
+				reason += "; the most scanned of its set"
+			}
+			items = append(items, TriageItem{Check: "duplicate", Index: ind, Reason: reason, Remedy: dropIndex(ind)})
+		}
+	}
+	for _, pair := range rp.RedundantIndexPairs {
+		items = append(items, TriageItem{
+			Check:  "redundant",
+			Index:  pair.Index,
+			Reason: "a prefix of " + pair.Covering.Name(),
+			Remedy: dropIndex(pair.Index),
+		})
+	}
+	for _, ind := range rp.InvalidIndexes {
+		remedy := invalidIndexRemedy(ind, rp.ServerVersion)
+		if !ind.IsLive() {
+			remedy = ""
+		}
+		items = append(items, TriageItem{Check: "invalid", Index: ind, Reason: ind.State(), Remedy: remedy})
+	}
+	for _, l := range rp.LowSelectivityIndexes {
+		items = append(items, TriageItem{
+			Check:  "lowselectivity",
+			Index:  l.Index,
+			Reason: fmt.Sprintf("%d distinct values in %s", int(l.Distinct), l.Column()),
+			Remedy: lowSelectivityRemedy(l),
+		})
+	}
+	for _, ind := range rp.getRelevantUnusedIndexes() {
+		items = append(items, TriageItem{
+			Check:  "unused",
+			Index:  ind,
+			Reason: fmt.Sprintf("scanned %d times", ind.NumScans()),
+			Remedy: dropIndex(ind),
+		})
+	}
+	return items
+}
+
+// Returns a statement that drops the index.
+func dropIndex(ind *catalog.Index) string {
+	return "DROP INDEX CONCURRENTLY " + pgx.Identifier{ind.Namespace(), ind.Name()}.Sanitize() + ";"
+}
+
+// Reports whether indexes contains ind.
+func containsIndex(indexes []*catalog.Index, ind *catalog.Index) bool {
+	for _, x := range indexes {
+		if x == ind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/jackc/pgx"
+)
+
+// metricsHandler serves the findings for a database as Prometheus metrics.
+// The findings are refreshed in the background, so a scrape never waits for
+// the catalog to load, and a slow or failed refresh never cuts one short: the
+// handler serves the last refresh that completed.
+type metricsHandler struct {
+	mu         sync.Mutex
+	metrics    []byte // of the last complete refresh; nil until one completes
+	incomplete bool   // the latest refresh failed, so metrics is out of date
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	metrics, incomplete := h.metrics, h.incomplete
+	h.mu.Unlock()
+	if metrics == nil {
+		http.Error(w, "no complete refresh yet", http.StatusServiceUnavailable)
+		return
+	}
+	flag := 0
+	if incomplete {
+		flag = 1
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(metrics)
+	fmt.Fprintf(w, "# HELP pglint_scrape_incomplete Whether the latest refresh failed or was interrupted, so that these metrics are from an earlier one.\n")
+	fmt.Fprintf(w, "# TYPE pglint_scrape_incomplete gauge\n")
+	fmt.Fprintf(w, "pglint_scrape_incomplete %d\n", flag)
+}
+
+// Refreshes the metrics now, and then every interval, until the process
+// exits. The next refresh starts an interval after the last one ends, by
+// which time db's cache, whose TTL is the interval, has expired. No request's
+// context is involved, so a scrape that times out cannot interrupt a refresh.
+func (h *metricsHandler) refresh(db *catalog.DB, connConf pgx.ConnConfig, opts reportOptions, interval time.Duration) {
+	for {
+		metrics, err := collectMetrics(context.Background(), db, connConf, opts)
+		if err != nil {
+			log.Printf("refreshing metrics: %+v", err)
+		}
+		h.mu.Lock()
+		if err == nil {
+			h.metrics = metrics
+		}
+		h.incomplete = err != nil
+		h.mu.Unlock()
+		time.Sleep(interval)
+	}
+}
+
+// Builds the report for db and renders it as metrics. A report that is
+// missing checks because it was interrupted is an error.
+func collectMetrics(ctx context.Context, db *catalog.DB, connConf pgx.ConnConfig, opts reportOptions) ([]byte, error) {
+	rp, err := buildReport(ctx, db, connConf, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(rp.Interrupted) > 0 {
+		return nil, fmt.Errorf("interrupted before checking %s", rp.FormatInterrupted())
+	}
+	var buf bytes.Buffer
+	if err := rp.GenerateMetrics(&buf); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&buf, "# HELP pglint_catalog_loaded_timestamp_seconds When the catalog was last loaded.\n")
+	fmt.Fprintf(&buf, "# TYPE pglint_catalog_loaded_timestamp_seconds gauge\n")
+	fmt.Fprintf(&buf, "pglint_catalog_loaded_timestamp_seconds %d\n", db.LoadedAt().Unix())
+	return buf.Bytes(), nil
+}
+
+// Serves metrics at /metrics on addr, refreshing them every interval. Only
+// returns on error.
+func serve(addr string, db *catalog.DB, connConf pgx.ConnConfig, opts reportOptions, interval time.Duration) error {
+	metrics := &metricsHandler{}
+	go metrics.refresh(db, connConf, opts, interval)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "pglint exporter for database %q; see /metrics\n", connConf.Database)
+	})
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("serving metrics for %s at %s/metrics", describeConn(connConf), addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/dcowgill/pglint/report"
+)
+
+// The marks a reviewer can give an item in the interactive triage.
+const (
+	markDrop        = "drop"
+	markKeep        = "keep"
+	markInvestigate = "investigate"
+)
+
+const tuiHelp = `Commands:
+  checks              list the checks and how many items each has
+  tables              list the tables and how many items each has
+  all                 list every item
+  check NAME          list the items of a check
+  table NAME          list the items on a table
+  show N              show the definition and statistics of item N
+  drop N...           mark items to be dropped (or otherwise fixed)
+  keep N...           mark items to be kept, and suppressed in future reports
+  investigate N...    mark items for further investigation
+  clear N...          remove the marks from items
+  marks               list the marked items
+  quit                write the output files and exit
+  abort               exit without writing anything
+  help                show this message
+Items are numbered as in "all"; N may also be a range, e.g. 3-7.
+`
+
+// triage is the state of an interactive review of a report's index findings.
+type triage struct {
+	rp     *report.Report
+	items  []report.TriageItem
+	marks  []string // parallel to items; empty if unmarked
+	tables map[string]*catalog.Table
+	out    io.Writer
+}
+
+// Runs the interactive triage of the index findings in rp. Commands are read
+// a line at a time from in, so it works in any terminal, or from a script. On
+// quit or at the end of input, the statements that fix the items marked
+// "drop" are written to sqlPath, along with the items marked "investigate" as
+// comments, and the items marked "keep" are appended to the suppression file
+// at suppressPath.
+func runTUI(rp *report.Report, in io.Reader, out io.Writer, sqlPath, suppressPath string) error {
+	t := &triage{
+		rp:     rp,
+		items:  rp.TriageItems(),
+		tables: make(map[string]*catalog.Table),
+		out:    out,
+	}
+	t.marks = make([]string, len(t.items))
+	for _, tbl := range rp.Tables {
+		t.tables[tbl.QualifiedName()] = tbl
+	}
+	fmt.Fprintf(out, "%d findings in database %q. Type \"help\" for a list of commands.\n",
+		len(t.items), rp.ConnConfig.Database)
+	if len(t.items) > 0 {
+		t.listChecks()
+	}
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "pglint> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			break
+		}
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		cmd, args := fields[0], fields[1:]
+		switch cmd {
+		case "quit", "q", "exit":
+			return t.writeFiles(sqlPath, suppressPath)
+		case "abort":
+			fmt.Fprintln(out, "Nothing written.")
+			return nil
+		}
+		if err := t.exec(cmd, args); err != nil {
+			fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return t.writeFiles(sqlPath, suppressPath)
+}
+
+// Executes a command other than quit and abort.
+func (t *triage) exec(cmd string, args []string) error {
+	switch cmd {
+	case "help", "h", "?":
+		fmt.Fprint(t.out, tuiHelp)
+	case "checks":
+		t.listChecks()
+	case "tables":
+		t.listTables()
+	case "all":
+		t.list(func(int) bool { return true })
+	case "check":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: check NAME")
+		}
+		t.list(func(i int) bool { return t.items[i].Check == args[0] })
+	case "table":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: table NAME")
+		}
+		t.list(func(i int) bool { return t.items[i].Index.QualifiedTableName() == args[0] })
+	case "show":
+		nums, err := t.parseItems(args)
+		if err != nil {
+			return err
+		}
+		for _, n := range nums {
+			t.show(n)
+		}
+	case markDrop, markKeep, markInvestigate, "clear":
+		nums, err := t.parseItems(args)
+		if err != nil {
+			return err
+		}
+		mark := cmd
+		if cmd == "clear" {
+			mark = ""
+		}
+		for _, n := range nums {
+			t.marks[n] = mark
+		}
+	case "marks":
+		t.list(func(i int) bool { return t.marks[i] != "" })
+	default:
+		return fmt.Errorf("unknown command %q; type \"help\" for a list of commands", cmd)
+	}
+	return nil
+}
+
+// Lists each check that has items, along with how many it has.
+func (t *triage) listChecks() {
+	var names []string
+	counts := make(map[string]int)
+	for _, it := range t.items {
+		if counts[it.Check] == 0 {
+			names = append(names, it.Check)
+		}
+		counts[it.Check]++
+	}
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%d\n", name, counts[name])
+	}
+	w.Flush()
+}
+
+// Lists each table that has items, along with how many it has, by name.
+func (t *triage) listTables() {
+	var names []string
+	counts := make(map[string]int)
+	for _, it := range t.items {
+		name := it.Index.QualifiedTableName()
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%d\n", name, counts[name])
+	}
+	w.Flush()
+}
+
+// Lists the items whose positions pred returns true for.
+func (t *triage) list(pred func(i int) bool) {
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+	n := 0
+	for i, it := range t.items {
+		if !pred(i) {
+			continue
+		}
+		fmt.Fprintf(w, "%4d\t%s\t%s\t%s\t%s\t%s\n", i+1, t.marks[i], it.Check,
+			it.Index.QualifiedTableName(), it.Index.Name(), it.Reason)
+		n++
+	}
+	w.Flush()
+	if n == 0 {
+		fmt.Fprintln(t.out, "No items.")
+	}
+}
+
+// Shows everything known about the item with the given (zero-based) number.
+func (t *triage) show(n int) {
+	it, ind := t.items[n], t.items[n].Index
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "#%d\t%s: %s\n", n+1, it.Check, it.Reason)
+	fmt.Fprintf(w, "Index\t%s (%s, %s)\n", ind.QualifiedName(), ind.Kind(), ind.State())
+	fmt.Fprintf(w, "Table\t%s\n", ind.QualifiedTableName())
+	if def := ind.Definition(); def != "" {
+		fmt.Fprintf(w, "Definition\t%s\n", def)
+	}
+	fmt.Fprintf(w, "Attrs\t%s\n", strings.Join(ind.Attrs(), ", "))
+	if ind.Pred() != "" {
+		fmt.Fprintf(w, "Predicate\t%s\n", ind.Pred())
+	}
+	if parts := ind.NumPartitions(); parts > 0 {
+		fmt.Fprintf(w, "Partitions\t%d\n", parts)
+	}
+	fmt.Fprintf(w, "Size\t%s\n", ind.Size().Human())
+	fmt.Fprintf(w, "Rows\t%d of the table's %d\n", ind.NumRows(), ind.NumTableRows())
+	fmt.Fprintf(w, "Scans\t%d\n", ind.NumScans())
+	fmt.Fprintf(w, "Tuples\t%d read, %d fetched\n", ind.NumTuplesRead(), ind.NumTuplesFetched())
+	if tbl := t.tables[ind.QualifiedTableName()]; tbl != nil {
+		fmt.Fprintf(w, "Table writes\t%d inserts, %d updates (%d HOT), %d deletes\n",
+			tbl.NumInserts(), tbl.NumUpdates(), tbl.NumHotUpdates(), tbl.NumDeletes())
+	}
+	if it.Remedy != "" {
+		fmt.Fprintf(w, "Remedy\t%s\n", it.Remedy)
+	}
+	if t.marks[n] != "" {
+		fmt.Fprintf(w, "Marked\t%s\n", t.marks[n])
+	}
+	w.Flush()
+}
+
+// Parses item numbers and ranges of item numbers, e.g. "3" and "5-7", into
+// zero-based positions in t.items.
+func (t *triage) parseItems(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no items given")
+	}
+	var nums []int
+	for _, arg := range args {
+		lo, hi := arg, arg
+		if i := strings.IndexByte(arg, '-'); i > 0 {
+			lo, hi = arg[:i], arg[i+1:]
+		}
+		first, err1 := strconv.Atoi(lo)
+		last, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || first < 1 || last > len(t.items) || first > last {
+			return nil, fmt.Errorf("invalid item %q: must be a number, or a range, from 1 to %d", arg, len(t.items))
+		}
+		for n := first; n <= last; n++ {
+			nums = append(nums, n-1)
+		}
+	}
+	return nums, nil
+}
+
+// Writes the remediation statements and appends the suppressions, if any
+// items were marked accordingly.
+func (t *triage) writeFiles(sqlPath, suppressPath string) error {
+	var drop, investigate, keep []int
+	for i, mark := range t.marks {
+		switch mark {
+		case markDrop:
+			drop = append(drop, i)
+		case markInvestigate:
+			investigate = append(investigate, i)
+		case markKeep:
+			keep = append(keep, i)
+		}
+	}
+	if len(drop) > 0 || len(investigate) > 0 {
+		if err := t.writeSQL(sqlPath, drop, investigate); err != nil {
+			return err
+		}
+		fmt.Fprintf(t.out, "Wrote %d statements and %d items to investigate to %s.\n",
+			len(drop), len(investigate), sqlPath)
+	}
+	if len(keep) > 0 {
+		if err := t.appendSuppressions(suppressPath, keep); err != nil {
+			return err
+		}
+		fmt.Fprintf(t.out, "Added %d suppressions to %s.\n", len(keep), suppressPath)
+	}
+	if len(drop)+len(investigate)+len(keep) == 0 {
+		fmt.Fprintln(t.out, "No items marked; nothing written.")
+	}
+	return nil
+}
+
+// Writes the statements that fix the items numbered drop, and describes the
+// items numbered investigate in comments. An index that appears in several
+// checks is only dropped once.
+func (t *triage) writeSQL(path string, drop, investigate []int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "-- Remediation for database %q, chosen with pglint tui at %s.\n",
+		t.rp.ConnConfig.Database, time.Now().Format(time.RFC1123))
+	fmt.Fprintf(w, "-- CONCURRENTLY cannot run in a transaction block: run each statement on its own.\n")
+	written := make(map[string]bool)
+	for _, n := range drop {
+		it := t.items[n]
+		fmt.Fprintf(w, "\n-- %s %s: %s\n", it.Check, it.Index.QualifiedName(), it.Reason)
+		switch {
+		case it.Remedy == "":
+			fmt.Fprintf(w, "-- no statement applies\n")
+		case written[it.Remedy]:
+			fmt.Fprintf(w, "-- see above\n")
+		default:
+			fmt.Fprintf(w, "%s\n", it.Remedy)
+			written[it.Remedy] = true
+		}
+	}
+	if len(investigate) > 0 {
+		fmt.Fprintf(w, "\n-- To investigate:\n")
+		for _, n := range investigate {
+			it := t.items[n]
+			fmt.Fprintf(w, "-- %s %s: %s\n", it.Check, it.Index.QualifiedName(), it.Reason)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Appends suppressions of the items numbered keep to the suppression file,
+// creating it if necessary.
+func (t *triage) appendSuppressions(path string, keep []int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# kept with pglint tui on %s\n", time.Now().Format("2006-01-02"))
+	for _, n := range keep {
+		it := t.items[n]
+		s := check.Suppression{Check: it.Check, Index: it.Index.QualifiedName()}
+		fmt.Fprintf(w, "%s # %s\n", s, it.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dcowgill/pglint/catalog"
+	"github.com/dcowgill/pglint/check"
+	"github.com/dcowgill/pglint/report"
+	"github.com/jackc/pgx"
+)
+
+// Builds a report of the shared fixture, whose index findings are triaged.
+func fixtureReport(t *testing.T) *report.Report {
+	t.Helper()
+	snap, err := catalog.ReadSnapshot("check/testdata/indexes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := reportOptions{unusedCutoff: 10, minIndexSize: 1, minIndexRows: 10}
+	rp, err := buildReport(context.Background(), snap, pgx.ConnConfig{Database: snap.Database}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+func TestTriageParseItems(t *testing.T) {
+	tr := &triage{items: make([]report.TriageItem, 8)}
+	nums, err := tr.parseItems([]string{"3", "5-7", "8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 4, 5, 6, 7}; !reflect.DeepEqual(nums, want) {
+		t.Errorf("got %v, want %v", nums, want)
+	}
+	for _, args := range [][]string{nil, {"0"}, {"9"}, {"x"}, {"7-5"}, {"5-9"}, {"-3"}} {
+		if _, err := tr.parseItems(args); err == nil {
+			t.Errorf("parseItems(%q): got no error", args)
+		}
+	}
+}
+
+func TestRunTUI(t *testing.T) {
+	rp := fixtureReport(t)
+	items := rp.TriageItems()
+	if len(items) < 3 {
+		t.Fatalf("got %d triage items, want at least 3", len(items))
+	}
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "remediation.sql")
+	suppressPath := filepath.Join(dir, "pglint.suppress")
+	if err := os.WriteFile(suppressPath, []byte("unused public.old_idx\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	script := "drop 1\nkeep 2\ninvestigate 3\nclear 3\ninvestigate 3\nfrobnicate\nshow 99\nquit\n"
+	if err := runTUI(rp, strings.NewReader(script), &out, sqlPath, suppressPath); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`unknown command "frobnicate"`,
+		`invalid item "99"`,
+		"Wrote 1 statements and 1 items to investigate",
+		"Added 1 suppressions",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	sql, err := os.ReadFile(sqlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\n" + items[0].Remedy + "\n",
+		"-- To investigate:\n-- " + items[2].Check + " " + items[2].Index.QualifiedName() + ": ",
+	} {
+		if !strings.Contains(string(sql), want) {
+			t.Errorf("remediation file does not contain %q:\n%s", want, sql)
+		}
+	}
+
+	// The suppression is appended, and the file still parses.
+	sup, err := check.ReadSuppressionFile(suppressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := check.Suppression{Check: items[1].Check, Index: items[1].Index.QualifiedName()}
+	if old := (check.Suppression{Check: "unused", Index: "public.old_idx"}); len(sup) != 2 || !sup[kept] || !sup[old] {
+		t.Errorf("got suppressions %v, want %v and %v", sup, old, kept)
+	}
+}
+
+func TestRunTUIAbort(t *testing.T) {
+	dir := t.TempDir()
+	sqlPath := filepath.Join(dir, "remediation.sql")
+	suppressPath := filepath.Join(dir, "pglint.suppress")
+	var out strings.Builder
+	if err := runTUI(fixtureReport(t), strings.NewReader("drop 1\nkeep 2\nabort\n"), &out, sqlPath, suppressPath); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{sqlPath, suppressPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was written after abort", filepath.Base(path))
+		}
+	}
+}